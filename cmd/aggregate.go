@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"github.com/benweidig/trex/nodes"
+)
+
+// aggregateCurrentNode aggregates the numbers of the array containing the current node.
+// Other arrays need the sub-path of the numbers, it's prompted and prefilled with the path of
+// the current node in its element. showFn displays the result.
+func aggregateCurrentNode(showFn func(array nodes.Node, subPath string, aggregate *nodes.Aggregate)) string {
+	node := uiNodeList.GetCurrentNode()
+	array := nodes.EnclosingArray(node)
+	if array == nil {
+		return "The selected node isn't in an array"
+	}
+
+	if nodes.IsNumberArray(array) {
+		aggregate, err := nodes.NewAggregate(array, "")
+		if err != nil {
+			return err.Error()
+		}
+		showFn(array, "", aggregate)
+		return ""
+	}
+
+	subPath := ""
+	for current := node; current != array; current = current.Parent() {
+		if current.Parent() == array {
+			subPath = nodes.RelativePath(current, node)
+			break
+		}
+	}
+
+	openEditBar(func() { uiEditBar.StartKey("aggregate", subPath) }, func(text string, _ nodes.ValueType) error {
+		aggregate, err := nodes.NewAggregate(array, text)
+		if err != nil {
+			return err
+		}
+		showFn(array, text, aggregate)
+		return nil
+	})
+	return ""
+}
+
+// aggregateInfo summarizes arrays of numbers for the status bar
+func aggregateInfo(node nodes.Node) string {
+	if nodes.IsNumberArray(node) == false {
+		return ""
+	}
+	aggregate, err := nodes.NewAggregate(node, "")
+	if err != nil {
+		return ""
+	}
+	return aggregate.String()
+}
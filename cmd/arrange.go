@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"github.com/benweidig/trex/nodes"
+)
+
+// arrangeKeys are the keys for sorting, filtering and grouping
+var arrangeKeys = map[rune]nodes.ArrangeKind{
+	'O': nodes.ArrangeSort,
+	'F': nodes.ArrangeFilter,
+	'G': nodes.ArrangeGroup,
+}
+
+// arrangePrompts are the labels of the edit bar for the expressions
+var arrangePrompts = map[nodes.ArrangeKind]string{
+	nodes.ArrangeSort:   "sort by (-desc)",
+	nodes.ArrangeFilter: "filter",
+	nodes.ArrangeGroup:  "group by",
+}
+
+// arrangeCurrentNode prompts for an expression and sorts, filters or groups the elements of the
+// array containing the current node. It's a derived view, unless in edit mode, where it's applied
+// as an edit that can be saved.
+func arrangeCurrentNode(kind nodes.ArrangeKind) string {
+	array := nodes.EnclosingArray(uiNodeList.GetCurrentNode())
+	if array == nil {
+		return "The selected node isn't in an array"
+	}
+
+	openEditBar(func() { uiEditBar.StartKey(arrangePrompts[kind], "") }, func(text string, _ nodes.ValueType) error {
+		arrangement, err := nodes.NewArrangement(kind, text)
+		if err != nil {
+			return err
+		}
+
+		if editMode && len(filterHistory) == 0 {
+			arranged, err := editTree.Arrange(array, arrangement)
+			if err != nil {
+				return err
+			}
+			refreshTree(arranged)
+			return nil
+		}
+
+		root, arranged, err := arrangement.View(uiNodeList.GetRoot(), array)
+		if err != nil {
+			return err
+		}
+		pushFilter(arrangement.String(), root)
+		uiNodeList.SelectNode(arranged)
+		return nil
+	})
+	return ""
+}
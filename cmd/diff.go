@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"github.com/benweidig/trex/nodes"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffKeysArg []string
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <file> <file>",
+	Short: "Structural diff of two files",
+	Long:  "Displays a merged tree of both files, with added, removed, changed and moved nodes marked",
+	Args:  cobra.ExactArgs(2),
+	Run:   runDiffCommand,
+}
+
+func init() {
+	diffCmd.Flags().StringSliceVarP(&diffKeysArg, "key", "k", nil, "Match array elements by these keys, e.g. name,id")
+	RootCmd.AddCommand(diffCmd)
+}
+
+func runDiffCommand(_ *cobra.Command, args []string) {
+	left, err := loadTree(args[0])
+	if err != nil {
+		panic(err)
+	}
+	right, err := loadTree(args[1])
+	if err != nil {
+		panic(err)
+	}
+
+	tree, err := nodes.Diff(left, right, diffKeysArg)
+	if err != nil {
+		panic(err)
+	}
+
+	treeInfo = nodes.Stats(tree.Root()).String()
+	runApp(tree, "")
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/benweidig/trex/nodes"
+)
+
+// duplicateIgnoreKeys are the last entered keys ignored when comparing subtrees
+var duplicateIgnoreKeys string
+
+// findDuplicates prompts for the keys to ignore, prefilled with the last ones, and passes them
+// to showFn for listing the duplicates
+func findDuplicates(showFn func(ignoreKeys []string)) {
+	openEditBar(func() { uiEditBar.StartKey("duplicates, ignoring keys (comma-separated)", duplicateIgnoreKeys) }, func(text string, _ nodes.ValueType) error {
+		duplicateIgnoreKeys = text
+
+		var ignoreKeys []string
+		for _, key := range strings.Split(text, ",") {
+			if key = strings.TrimSpace(key); len(key) > 0 {
+				ignoreKeys = append(ignoreKeys, key)
+			}
+		}
+		showFn(ignoreKeys)
+		return nil
+	})
+}
@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+
+	"github.com/benweidig/trex/input"
+	"github.com/benweidig/trex/nodes"
+	"github.com/benweidig/trex/widgets"
+)
+
+var (
+	uiEditBar *widgets.EditBar
+
+	// editTree is the tree of the source file, only set if it can be edited and saved
+	editTree       *nodes.Tree
+	editSourcePath string
+	editMode       bool
+
+	// editDoneFn handles the input of the edit bar, it's replaced for every prompt
+	editDoneFn func(text string, valueType nodes.ValueType) error
+)
+
+// toggleEditMode enables/disables the edit keys and returns the info for the status bar
+func toggleEditMode() string {
+	if editTree == nil {
+		return "Only files can be edited, not diffs or merges"
+	}
+	if len(editSourcePath) == 0 {
+		return "Piped input can't be edited"
+	}
+	if len(filterHistory) > 0 {
+		return "Nodes can't be edited while a filter is active"
+	}
+
+	editMode = editMode == false
+	uiNodeList.TriggerChanged()
+	return ""
+}
+
+// editCurrentNode runs the edit action of the key and returns the info for the status bar
+func editCurrentNode(key rune) string {
+	if len(filterHistory) > 0 {
+		return "Nodes can't be edited while a filter is active"
+	}
+
+	switch key {
+	case 'v':
+		return editValue()
+	case 'r':
+		return renameKey()
+	case 'a':
+		return addNode()
+	case 'x':
+		return deleteNode()
+	case 'y':
+		return duplicateNode()
+	case 'J':
+		return moveNode(1)
+	case 'K':
+		return moveNode(-1)
+	case 's':
+		return saveTree()
+	default:
+		return ""
+	}
+}
+
+// editValue prompts for a new value of the current scalar node
+func editValue() string {
+	node := uiNodeList.GetCurrentNode()
+	text, valueType, ok := nodes.EditableValue(node)
+	if ok == false {
+		return "Only scalar values can be edited"
+	}
+
+	openEditBar(func() { uiEditBar.StartValue("value", text, valueType) }, func(text string, valueType nodes.ValueType) error {
+		value, err := nodes.ParseValue(text, valueType)
+		if err != nil {
+			return err
+		}
+		edited, err := editTree.SetValue(node, value)
+		if err != nil {
+			return err
+		}
+		refreshTree(edited)
+		return nil
+	})
+	return ""
+}
+
+// renameKey prompts for a new key of the current object member
+func renameKey() string {
+	node := uiNodeList.GetCurrentNode()
+	key, ok := nodes.EditableKey(node)
+	if ok == false {
+		return "Only object members have a key"
+	}
+
+	openEditBar(func() { uiEditBar.StartKey("key", key) }, func(text string, _ nodes.ValueType) error {
+		if err := editTree.RenameKey(node, text); err != nil {
+			return err
+		}
+		refreshTree(node)
+		return nil
+	})
+	return ""
+}
+
+// addNode prompts for a new child of the current object/array, or a sibling of other nodes.
+// Objects need a key first, so two prompts are chained.
+func addNode() string {
+	parent, needsKey := nodes.AddTarget(uiNodeList.GetCurrentNode())
+
+	addValue := func(key string) {
+		openEditBar(func() { uiEditBar.StartValue("new value", "", nodes.ValueTypeString) }, func(text string, valueType nodes.ValueType) error {
+			value, err := nodes.ParseValue(text, valueType)
+			if err != nil {
+				return err
+			}
+			added, err := editTree.Add(parent, key, value)
+			if err != nil {
+				return err
+			}
+			refreshTree(added)
+			return nil
+		})
+	}
+
+	if needsKey {
+		openEditBar(func() { uiEditBar.StartKey("new key", "") }, func(text string, _ nodes.ValueType) error {
+			if len(text) == 0 {
+				return errors.New("Keys can't be empty")
+			}
+			addValue(text)
+			return nil
+		})
+		return ""
+	}
+	addValue("")
+	return ""
+}
+
+// deleteNode removes the current node and selects the item taking its place
+func deleteNode() string {
+	idx := uiNodeList.GetCurrentIdx()
+	if err := editTree.Delete(uiNodeList.GetCurrentNode()); err != nil {
+		return err.Error()
+	}
+
+	refreshTree(nil)
+	if count := len(uiNodeList.GetItems()); idx >= count {
+		idx = count - 1
+	}
+	uiNodeList.SetCurrentItem(idx)
+	uiNodeList.TriggerChanged()
+	return ""
+}
+
+// duplicateNode inserts a copy of the current node and selects it
+func duplicateNode() string {
+	duplicate, err := editTree.Duplicate(uiNodeList.GetCurrentNode())
+	if err != nil {
+		return err.Error()
+	}
+	refreshTree(duplicate)
+	return ""
+}
+
+// moveNode moves the current array element by the offset
+func moveNode(offset int) string {
+	node := uiNodeList.GetCurrentNode()
+	if err := editTree.Move(node, offset); err != nil {
+		return err.Error()
+	}
+	refreshTree(node)
+	return ""
+}
+
+// saveTree writes the edited tree back to the source file in its original format
+func saveTree() string {
+	fileType := editTree.FileType()
+	if fileType == input.FileTypeUnknown {
+		fileType = input.FileTypeJSON
+	}
+	bytes := nodes.Serialize(editTree.Root(), fileType)
+
+	err := ioutil.WriteFile(editSourcePath, bytes, 0644)
+	if err != nil {
+		return err.Error()
+	}
+
+	editTree.MarkSaved(input.Locate(fileType, bytes))
+	uiNodeList.TriggerChanged()
+	return "Saved to " + editSourcePath
+}
+
+// submitEditBar passes the entered text to the handler of the prompt, errors keep the bar open
+func submitEditBar(text string, valueType nodes.ValueType) {
+	doneFn := editDoneFn
+	editDoneFn = nil
+	if err := doneFn(text, valueType); err != nil {
+		editDoneFn = doneFn
+		uiStatusBar.SetInfo(err.Error())
+		return
+	}
+
+	// Chained prompts set a new handler and need the bar to stay open
+	if editDoneFn == nil {
+		closeEditBar()
+	}
+}
+
+// openEditBar shows the edit bar prepared by startFn, doneFn handles the entered text
+func openEditBar(startFn func(), doneFn func(text string, valueType nodes.ValueType) error) {
+	editDoneFn = doneFn
+	startFn()
+	mainPage.ResizeItem(uiEditBar, 1, 0)
+	app.SetFocus(uiEditBar)
+}
+
+// closeEditBar hides the edit bar and returns the focus to the tree
+func closeEditBar() {
+	editDoneFn = nil
+	mainPage.ResizeItem(uiEditBar, 0, 0)
+	// The handler might have opened a popup, it keeps the focus
+	if uiEditBar.HasFocus() {
+		app.SetFocus(uiNodeList)
+	}
+}
+
+// editInfo shows the edit mode and unsaved changes in the status bar
+func editInfo() string {
+	switch {
+	case editTree == nil:
+		return ""
+	case editMode && editTree.IsDirty():
+		return "EDIT [modified]"
+	case editMode:
+		return "EDIT"
+	case editTree.IsDirty():
+		return "[modified]"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/benweidig/trex/input"
+)
+
+const fallbackEditor = "vi"
+
+// editorCommand prepares $EDITOR to open the file at the line of the position.
+// Everything that can go wrong is checked here, before the terminal is handed over.
+func editorCommand(path string, position input.Position) (*exec.Cmd, error) {
+	if len(path) == 0 {
+		return nil, errors.New("Piped input can't be opened in an editor")
+	}
+	if position.IsKnown() == false {
+		return nil, errors.New("The selected value isn't part of the file")
+	}
+
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{fallbackEditor}
+	}
+	if _, err := exec.LookPath(editor[0]); err != nil {
+		return nil, fmt.Errorf("Editor %s not found", editor[0])
+	}
+
+	args := append(editor[1:], fmt.Sprintf("+%d", position.Line), path)
+	cmd := exec.Command(editor[0], args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd, nil
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/benweidig/trex/input"
+	"github.com/benweidig/trex/jmespath"
+	"github.com/benweidig/trex/jq"
+	"github.com/benweidig/trex/nodes"
+	"github.com/benweidig/trex/widgets"
+)
+
+// filterStep is an applied jq/JMESPath filter or another derived view, with the root it was
+// applied to, so we can step back.
+type filterStep struct {
+	description string
+	root        nodes.Node
+}
+
+// filterHistory contains all applied filters, the latest at the end.
+var filterHistory []filterStep
+
+// applyFilter runs the jq/JMESPath filter against the node and builds a new root from the result.
+// A single jq result is used as-is, multiple results are wrapped into an array.
+func applyFilter(node nodes.Node, language widgets.QueryLanguage, expression string, fileType input.FileType) (nodes.Node, error) {
+	var raw interface{}
+	switch language {
+	case widgets.QueryLanguageJQ:
+		results, err := jq.Evaluate(expression, nodes.ToRaw(node))
+		if err != nil {
+			return nil, err
+		}
+		if len(results) == 1 {
+			raw = results[0]
+		} else {
+			raw = append([]interface{}{}, results...)
+		}
+
+	case widgets.QueryLanguageJMESPath:
+		result, err := jmespath.Search(expression, nodes.ToRaw(node))
+		if err != nil {
+			return nil, err
+		}
+		raw = result
+
+	default:
+		return nil, fmt.Errorf("%s can't be used as a filter", language)
+	}
+
+	tree, err := nodes.NewTree(fileType, raw, nil)
+	if err != nil {
+		return nil, err
+	}
+	return tree.Root(), nil
+}
+
+// filterInfo describes the active filter for the status bar
+func filterInfo() string {
+	if len(filterHistory) == 0 {
+		return ""
+	}
+	return filterHistory[len(filterHistory)-1].description
+}
+
+// pushFilter displays the filtered root and records the filter in the history, so it can be undone
+func pushFilter(description string, root nodes.Node) {
+	step := filterStep{
+		description: description,
+		root:        uiNodeList.GetRoot(),
+	}
+	apply := func() nodes.Node {
+		filterHistory = append(filterHistory, step)
+		setDisplayedRoot(root)
+		return nil
+	}
+	revert := func() nodes.Node {
+		filterHistory = filterHistory[:len(filterHistory)-1]
+		setDisplayedRoot(step.root)
+		return nil
+	}
+
+	apply()
+	currentTree.History().Record(nodes.NewCommand(description, step.root.Path(), revert, apply))
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"github.com/benweidig/trex/nodes"
+)
+
+// currentTree is the displayed tree, all its changes are recorded in its history
+var currentTree *nodes.Tree
+
+// undo reverts the last change and returns the info for the status bar
+func undo() string {
+	history := currentTree.History()
+	selected, err := history.Undo()
+	if err != nil {
+		return err.Error()
+	}
+	if selected != nil {
+		refreshTree(selected)
+	}
+	return "Undone: " + history.Commands()[history.Applied()].Description
+}
+
+// redo applies the last undone change again and returns the info for the status bar
+func redo() string {
+	history := currentTree.History()
+	selected, err := history.Redo()
+	if err != nil {
+		return err.Error()
+	}
+	if selected != nil {
+		refreshTree(selected)
+	}
+	return "Redone: " + history.Commands()[history.Applied()-1].Description
+}
+
+// jumpToHistory undoes/redoes changes until the count of applied changes is reached
+func jumpToHistory(applied int) {
+	history := currentTree.History()
+
+	// Only the last change decides about the display, filters update it themselves
+	var selected nodes.Node
+	for history.Applied() > applied {
+		selected, _ = history.Undo()
+	}
+	for history.Applied() < applied {
+		selected, _ = history.Redo()
+	}
+
+	if selected != nil {
+		refreshTree(selected)
+	}
+}
+
+// refreshTree rebuilds the displayed tree after a change and selects the node
+func refreshTree(selected nodes.Node) {
+	root := currentTree.Root()
+	validateTree()
+	uiNodeList.SetRoot(root)
+	if changedOnly {
+		uiNodeList.SetMatches(nodes.Changed(root), true)
+	}
+	if selected != nil {
+		uiNodeList.SelectNode(selected)
+	}
+
+	if currentMerge != nil {
+		treeInfo = mergeInfo()
+	}
+	uiNodeList.TriggerChanged()
+}
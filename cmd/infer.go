@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/benweidig/trex/input"
+	"github.com/benweidig/trex/nodes"
+	"github.com/benweidig/trex/schema"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	inferFormatArg  string
+	inferSamplesArg bool
+)
+
+var inferCmd = &cobra.Command{
+	Use:   "infer <file>...",
+	Short: "Infer a JSON Schema from samples",
+	Long:  "Prints a JSON Schema all files are valid against, detecting optional keys, enums and string formats",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runInferCommand,
+	// Errors are about the files or flags, not the usage
+	SilenceUsage: true,
+}
+
+func init() {
+	inferCmd.Flags().StringVar(&inferFormatArg, "format", "", "Output format: json or yaml (default: format of the first file)")
+	inferCmd.Flags().BoolVar(&inferSamplesArg, "samples", false, "Treat the elements of root arrays as separate samples")
+	RootCmd.AddCommand(inferCmd)
+}
+
+func runInferCommand(_ *cobra.Command, args []string) error {
+	var fileType input.FileType
+	switch inferFormatArg {
+	case "":
+	case "json":
+		fileType = input.FileTypeJSON
+	case "yaml":
+		fileType = input.FileTypeYAML
+	default:
+		return fmt.Errorf("Unknown format '%s', use json or yaml", inferFormatArg)
+	}
+
+	var samples []interface{}
+	for idx, path := range args {
+		tree, err := loadTree(path)
+		if err != nil {
+			return err
+		}
+		if idx == 0 && len(inferFormatArg) == 0 {
+			fileType = tree.FileType()
+		}
+
+		raw := nodes.ToRaw(tree.Root())
+		if elements, isArray := raw.([]interface{}); isArray && inferSamplesArg {
+			samples = append(samples, elements...)
+		} else {
+			samples = append(samples, raw)
+		}
+	}
+
+	tree, err := nodes.NewTree(fileType, schema.Infer(samples...), nil)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(nodes.Serialize(tree.Root(), fileType))
+	return err
+}
+
+// inferCurrentNode displays the inferred schema of the current node, it can be undone like a filter
+func inferCurrentNode() string {
+	node := uiNodeList.GetCurrentNode()
+	tree, err := nodes.NewTree(currentTree.FileType(), schema.Infer(nodes.ToRaw(node)), nil)
+	if err != nil {
+		return err.Error()
+	}
+	pushFilter("schema of "+node.Path(), tree.Root())
+	return ""
+}
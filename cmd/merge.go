@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/benweidig/trex/input"
+	"github.com/benweidig/trex/nodes"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	mergeOutputArg string
+
+	// currentMerge is only set in merge mode
+	currentMerge *nodes.Merge
+)
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge <base> <ours> <theirs>",
+	Short: "Structural three-way merge",
+	Long:  "Merges the changes of ours and theirs, conflicts can be resolved interactively and the result written with the selected formatter",
+	Args:  cobra.ExactArgs(3),
+	Run:   runMergeCommand,
+}
+
+func init() {
+	mergeCmd.Flags().StringVarP(&mergeOutputArg, "output", "o", "", "File to write the result to (default: <ours>.merged.<ext>)")
+	RootCmd.AddCommand(mergeCmd)
+}
+
+func runMergeCommand(_ *cobra.Command, args []string) {
+	trees := make([]*nodes.Tree, len(args))
+	for idx, path := range args {
+		tree, err := loadTree(path)
+		if err != nil {
+			panic(err)
+		}
+		trees[idx] = tree
+	}
+
+	merge, err := nodes.NewMerge(trees[0], trees[1], trees[2])
+	if err != nil {
+		panic(err)
+	}
+
+	// The inputs are only overwritten if requested explicitly
+	if len(mergeOutputArg) == 0 {
+		mergeOutputArg = mergedPath(args[1])
+	}
+	currentMerge = merge
+	treeInfo = mergeInfo()
+	runApp(merge.Tree(), "")
+}
+
+// mergeSides maps the keys for resolving to the sides
+var mergeSides = map[rune]nodes.MergeSide{
+	'b': nodes.MergeSideBase,
+	'o': nodes.MergeSideOurs,
+	't': nodes.MergeSideTheirs,
+}
+
+// resolveCurrentNode replaces the current node with a side of the merge and returns the info for the status bar
+func resolveCurrentNode(side nodes.MergeSide) string {
+	if len(filterHistory) > 0 {
+		return "Conflicts can't be resolved while a filter is active"
+	}
+
+	resolved, err := currentMerge.Resolve(uiNodeList.GetCurrentNode(), side)
+	if err != nil {
+		return err.Error()
+	}
+
+	refreshTree(resolved)
+	return ""
+}
+
+// writeMerge writes the merged tree with the current formatter, but only without conflicts
+func writeMerge() string {
+	if conflicts := len(currentMerge.Conflicts()); conflicts > 0 {
+		return fmt.Sprintf("%d conflicts left, nothing written", conflicts)
+	}
+
+	fileType := formatterFileType
+	if fileType == input.FileTypeUnknown {
+		fileType = currentMerge.Tree().FileType()
+	}
+	err := ioutil.WriteFile(mergeOutputArg, nodes.Serialize(currentMerge.Tree().Root(), fileType), 0644)
+	if err != nil {
+		return err.Error()
+	}
+	currentMerge.MarkWritten()
+	treeInfo = mergeInfo()
+	return "Written to " + mergeOutputArg
+}
+
+// mergedPath is the default output next to ours, like config.merged.yaml for config.yaml
+func mergedPath(ours string) string {
+	ext := filepath.Ext(ours)
+	return strings.TrimSuffix(ours, ext) + ".merged" + ext
+}
+
+func mergeInfo() string {
+	info := fmt.Sprintf("%d conflicts", len(currentMerge.Conflicts()))
+	if len(currentMerge.Conflicts()) == 0 {
+		info = "No conflicts"
+	}
+	if currentMerge.IsDirty() {
+		info += " [unwritten]"
+	}
+	return info
+}
+
+// hasUnsavedChanges reports whether quitting would lose edits or an unwritten merge result
+func hasUnsavedChanges() bool {
+	if currentMerge != nil {
+		return currentMerge.IsDirty()
+	}
+	return editTree != nil && editTree.IsDirty()
+}
+
+// saveChanges saves the edits or writes the merge result, the info is for the status bar
+func saveChanges() string {
+	if currentMerge != nil {
+		return writeMerge()
+	}
+	return saveTree()
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 
+	"github.com/benweidig/trex/codegen"
 	"github.com/benweidig/trex/input"
 	"github.com/benweidig/trex/nodes"
 	"github.com/benweidig/trex/ui"
@@ -21,6 +23,7 @@ import (
 
 var (
 	monochromeArg bool
+	depthArg      int
 )
 
 // RootCmd is the only command, so this is t-rex
@@ -42,29 +45,49 @@ var (
 	pages             = tview.NewPages()
 	uiNodeList        *widgets.NodeList
 	uiOutput          *widgets.Output
+	uiQueryBar        *widgets.QueryBar
+	uiSearchBar       *widgets.SearchBar
+	mainPage          *tview.Flex
 	uiStatusBar       = widgets.NewStatusBar()
 	leftToRightRatio  = 3
 	formatterFileType input.FileType
+	codeLanguage      codegen.Language
+	pathSyntax        nodes.PathSyntax = nodes.PathSyntaxJSONPath
+	treeInfo          string
+	changedOnly       bool
 	topContentFlex    *tview.Flex
 )
 
 func init() {
 	ui.ApplyStyling()
-	RootCmd.Flags().BoolVarP(&monochromeArg, "monochrome", "m", false, "Monochrome output, no ANSI colors")
+	RootCmd.PersistentFlags().BoolVarP(&monochromeArg, "monochrome", "m", false, "Monochrome output, no ANSI colors")
+	RootCmd.PersistentFlags().IntVar(&depthArg, "depth", 0, "Initially expanded levels of the tree (default: all)")
+	RootCmd.Flags().StringVar(&schemaArg, "schema", "", "JSON Schema to validate against (default: $schema of the document)")
 }
 
 func runCommand(_ *cobra.Command, args []string) {
-	bytes, fileType, err := getBytes(args)
+	bytes, sourcePath, fileType, err := getBytes(args)
 	if err != nil {
 		panic(err)
 	}
 
 	raw, err := input.Load(fileType, bytes)
-	tree, err := nodes.NewTree(fileType, raw)
+	tree, err := nodes.NewTree(fileType, raw, input.Locate(fileType, bytes))
 	if err != nil {
 		panic(err)
 	}
 
+	if err := setupSchema(tree, sourcePath); err != nil {
+		panic(err)
+	}
+
+	editTree = tree
+	editSourcePath = sourcePath
+	runApp(tree, sourcePath)
+}
+
+// runApp displays the tree until the user quits, the source path is empty for piped input
+func runApp(tree *nodes.Tree, sourcePath string) {
 	// Check if the terminal actually supports colors
 	monochromeArg = monochromeArg || os.Getenv("TERM") == "dumb" ||
 		(!isatty.IsTerminal(os.Stdout.Fd()) && !isatty.IsCygwinTerminal(os.Stdout.Fd()))
@@ -80,33 +103,223 @@ func runCommand(_ *cobra.Command, args []string) {
 		if formatterFileType == input.FileTypeUnknown {
 			formatterFileType = tree.FileType()
 		}
-		f := nodes.BuildFormatter(2, monochromeArg, formatterFileType)
-		node.Format(f, 1)
-		uiOutput.SetText(f.String())
-		uiStatusBar.SetContent(node.Path(), formatterFileType)
+		text := formatNode(node, monochromeArg)
+		if highlight := uiNodeList.GetHighlight(); highlight != nil && monochromeArg == false {
+			text = highlight.Highlight(text)
+		}
+		uiOutput.SetText(text)
+		uiStatusBar.SetContent(nodes.BuildPath(node, pathSyntax), formatterFileType)
+		uiStatusBar.SetPosition(node.Position())
+		uiStatusBar.SetInfo(statusInfo(treeInfo, editInfo(), filterInfo(), matchInfo(uiNodeList.GetMatchPosition()), schemaInfo(node), sizeInfo(node), aggregateInfo(node)))
 	})
+	uiStatusBar.SetSource(sourcePath)
+	currentTree = tree
+	validateTree()
+	if depthArg > 0 {
+		nodes.ExpandToDepth(tree.Root(), depthArg)
+	}
 	uiNodeList.SetRoot(tree.Root())
 
 	outputPopup := widgets.NewFormatterPopup(func(selected input.FileType) {
 		formatterFileType = selected
+		codeLanguage = ""
+		uiNodeList.TriggerChanged()
+		pages.SwitchToPage(widgets.MainPage)
+		app.SetFocus(uiNodeList)
+	}, func(selected codegen.Language) {
+		codeLanguage = selected
+		uiNodeList.TriggerChanged()
+		pages.SwitchToPage(widgets.MainPage)
+		app.SetFocus(uiNodeList)
+	})
+
+	pathSyntaxPopup := widgets.NewPathSyntaxPopup(func(selected nodes.PathSyntax) {
+		pathSyntax = selected
 		uiNodeList.TriggerChanged()
 		pages.SwitchToPage(widgets.MainPage)
 		app.SetFocus(uiNodeList)
 	})
 
+	pathFinder := widgets.NewPathFinder(monochromeArg, func(node nodes.Node) {
+		pages.SwitchToPage(widgets.MainPage)
+		uiNodeList.SelectNode(node)
+		app.SetFocus(uiNodeList)
+	})
+	pathFinderPopup := ui.NewPopup(pathFinder)
+
 	helpPopup := widgets.NewHelpPopup()
 
-	mainPage, topContentFlex := widgets.NewMainPage(leftToRightRatio, uiNodeList, uiOutput, uiStatusBar)
+	sizeBreakdown := widgets.NewSizeBreakdown(monochromeArg)
+
+	tableView := widgets.NewTableView(monochromeArg, func(node nodes.Node) {
+		pages.SwitchToPage(widgets.MainPage)
+		uiNodeList.SelectNode(node)
+		app.SetFocus(uiNodeList)
+	})
+
+	aggregateView := widgets.NewAggregateView(monochromeArg)
+	aggregatePopup := ui.NewPopup(aggregateView)
+
+	fieldProfileList := widgets.NewFieldProfileList(monochromeArg, func(node nodes.Node) {
+		pages.SwitchToPage(widgets.MainPage)
+		uiNodeList.SelectNode(node)
+		app.SetFocus(uiNodeList)
+	})
+	fieldProfilePopup := ui.NewPopup(fieldProfileList)
+
+	duplicateList := widgets.NewDuplicateList(monochromeArg, func(node nodes.Node) {
+		pages.SwitchToPage(widgets.MainPage)
+		uiNodeList.SelectNode(node)
+		app.SetFocus(uiNodeList)
+	})
+	duplicatePopup := ui.NewPopup(duplicateList)
+
+	violationList := widgets.NewViolationList(func(node nodes.Node) {
+		pages.SwitchToPage(widgets.MainPage)
+		uiNodeList.SelectNode(node)
+		app.SetFocus(uiNodeList)
+	})
+	violationPopup := ui.NewPopup(violationList)
+
+	historyList := widgets.NewHistoryList(monochromeArg, func(applied int) {
+		pages.SwitchToPage(widgets.MainPage)
+		jumpToHistory(applied)
+		app.SetFocus(uiNodeList)
+	})
+	historyPopup := ui.NewPopup(historyList)
+
+	uiQueryBar = widgets.NewQueryBar(func(language widgets.QueryLanguage, query string) {
+		if len(query) == 0 {
+			uiNodeList.ClearMatches()
+			mainPage.ResizeItem(uiQueryBar, 0, 0)
+			app.SetFocus(uiNodeList)
+			return
+		}
+
+		if language != widgets.QueryLanguageJSONPath {
+			root, err := applyFilter(uiNodeList.GetRoot(), language, query, tree.FileType())
+			if err != nil {
+				uiStatusBar.SetInfo(err.Error())
+				return
+			}
+
+			pushFilter(fmt.Sprintf("%s: %s", language, query), root)
+			uiQueryBar.SetText("")
+			mainPage.ResizeItem(uiQueryBar, 0, 0)
+			app.SetFocus(uiNodeList)
+			return
+		}
+
+		matches, err := nodes.QueryJSONPath(uiNodeList.GetRoot(), query)
+		if err != nil {
+			uiStatusBar.SetInfo(err.Error())
+			return
+		}
+		if len(matches) == 0 {
+			uiStatusBar.SetInfo("No matches")
+			return
+		}
+
+		uiNodeList.SetHighlight(nil)
+		uiNodeList.SetMatches(matches, true)
+		app.SetFocus(uiNodeList)
+	}, func() {
+		uiNodeList.ClearMatches()
+		mainPage.ResizeItem(uiQueryBar, 0, 0)
+		app.SetFocus(uiNodeList)
+	})
+
+	// searchStart is the node selected when the search started, matches are searched from there
+	var searchStart nodes.Node
+	uiSearchBar = widgets.NewSearchBar(func(pattern string, mode nodes.SearchMode) {
+		if len(pattern) == 0 {
+			uiNodeList.ClearMatches()
+			if searchStart != nil {
+				uiNodeList.SelectNode(searchStart)
+			}
+			return
+		}
+
+		matcher, err := nodes.NewMatcher(pattern, mode)
+		if err != nil {
+			uiStatusBar.SetInfo(err.Error())
+			return
+		}
+
+		matches := nodes.Search(uiNodeList.GetRoot(), matcher)
+		uiNodeList.SetHighlight(matcher)
+		uiNodeList.SetMatches(matches, false)
+		uiNodeList.SetBackward(uiSearchBar.IsBackward())
+		if len(matches) == 0 {
+			uiNodeList.SelectNode(searchStart)
+			uiStatusBar.SetInfo("No matches")
+			return
+		}
+		uiNodeList.SelectMatchFrom(searchStart, uiSearchBar.IsBackward())
+	}, func() {
+		mainPage.ResizeItem(uiSearchBar, 0, 0)
+		app.SetFocus(uiNodeList)
+	}, func() {
+		uiNodeList.ClearMatches()
+		uiNodeList.SelectNode(searchStart)
+		mainPage.ResizeItem(uiSearchBar, 0, 0)
+		app.SetFocus(uiNodeList)
+	})
+
+	uiEditBar = widgets.NewEditBar(submitEditBar, closeEditBar)
+
+	quitConfirmation := widgets.NewQuitConfirmation(func(label string) {
+		switch label {
+		case "Save":
+			if info := saveChanges(); hasUnsavedChanges() {
+				uiStatusBar.SetInfo(info)
+				break
+			}
+			app.Stop()
+			return
+
+		case "Discard":
+			app.Stop()
+			return
+		}
+		pages.SwitchToPage(widgets.MainPage)
+		app.SetFocus(uiNodeList)
+	})
+
+	mainPage, topContentFlex = widgets.NewMainPage(leftToRightRatio, uiNodeList, uiOutput, uiQueryBar, uiSearchBar, uiEditBar, uiStatusBar)
 
 	pages.
 		AddPage(widgets.MainPage, mainPage, true, true).
+		AddPage(widgets.SizePage, sizeBreakdown, true, false).
+		AddPage(widgets.TablePage, tableView, true, false).
 		AddPage(widgets.FormatterPopupPage, outputPopup, true, false).
-		AddPage(widgets.HelpPopupPage, helpPopup, true, false)
+		AddPage(widgets.PathSyntaxPopupPage, pathSyntaxPopup, true, false).
+		AddPage(widgets.PathFinderPopupPage, pathFinderPopup, true, false).
+		AddPage(widgets.HelpPopupPage, helpPopup, true, false).
+		AddPage(widgets.HistoryPopupPage, historyPopup, true, false).
+		AddPage(widgets.ViolationPopupPage, violationPopup, true, false).
+		AddPage(widgets.AggregatePopupPage, aggregatePopup, true, false).
+		AddPage(widgets.FieldProfilePopupPage, fieldProfilePopup, true, false).
+		AddPage(widgets.DuplicatePopupPage, duplicatePopup, true, false).
+		AddPage(widgets.QuitConfirmPage, quitConfirmation, true, false)
 
 	app.
 		SetRoot(pages, true).
 		SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 
+			// The query, search and edit bars need all the keys for typing
+			if uiQueryBar.HasFocus() || uiSearchBar.HasFocus() || uiEditBar.HasFocus() {
+				return event
+			}
+
+			// Unsaved changes and merge results need to be confirmed before quitting
+			if event.Key() == tcell.KeyCtrlC && hasUnsavedChanges() {
+				pages.ShowPage(widgets.QuitConfirmPage)
+				app.SetFocus(quitConfirmation)
+				app.Draw()
+				return nil
+			}
+
 			// Most events should only be accessible when no popup is active
 			if mainPage.GetFocusable().HasFocus() {
 
@@ -119,24 +332,236 @@ func runCommand(_ *cobra.Command, args []string) {
 						app.Draw()
 						return nil
 
-					case '?': // Help
-						pages.ShowPage(widgets.HelpPopupPage)
-						app.SetFocus(helpPopup)
+					case '/', '?': // Incremental search, forward or backward
+						searchStart = uiNodeList.GetCurrentNode()
+						uiSearchBar.Start(event.Rune() == '?')
+						mainPage.ResizeItem(uiSearchBar, 1, 0)
+						app.SetFocus(uiSearchBar)
 						app.Draw()
 						return nil
 
 					case 'c': // Copy
-						f := nodes.BuildFormatter(2, true, formatterFileType)
+						err := clipboard.WriteAll(formatNode(uiNodeList.GetCurrentNode(), true))
+						if err != nil {
+							panic(err)
+						}
+						app.Draw()
+						return nil
+
+					case ':': // Query
+						mainPage.ResizeItem(uiQueryBar, 1, 0)
+						app.SetFocus(uiQueryBar)
+						app.Draw()
+						return nil
+
+					case 'P': // Choose path syntax
+						pages.ShowPage(widgets.PathSyntaxPopupPage)
+						app.SetFocus(pathSyntaxPopup)
+						app.Draw()
+						return nil
+
+					case 'p': // Copy path
 						node := uiNodeList.GetCurrentNode()
-						node.Format(f, 1)
-						err := clipboard.WriteAll(f.String())
+						err := clipboard.WriteAll(nodes.BuildPath(node, pathSyntax))
 						if err != nil {
 							panic(err)
 						}
+						return nil
+
+					case 'C': // Toggle showing only changed nodes of a diff/merge
+						if changedOnly {
+							changedOnly = false
+							uiNodeList.ClearMatches()
+							return nil
+						}
+						changed := nodes.Changed(uiNodeList.GetRoot())
+						if len(changed) == 0 {
+							uiStatusBar.SetInfo("No changes")
+							return nil
+						}
+						changedOnly = true
+						uiNodeList.SetHighlight(nil)
+						uiNodeList.SetMatches(changed, true)
+						return nil
+
+					case 'b', 'o', 't': // Resolve with base/ours/theirs (merge)
+						if currentMerge == nil {
+							return event
+						}
+						if info := resolveCurrentNode(mergeSides[event.Rune()]); len(info) > 0 {
+							uiStatusBar.SetInfo(info)
+						}
+						app.Draw()
+						return nil
+
+					case 'w': // Write merge result
+						if currentMerge == nil {
+							return event
+						}
+						uiStatusBar.SetInfo(writeMerge())
+						app.Draw()
+						return nil
+
+					case 'i': // Toggle edit mode
+						if info := toggleEditMode(); len(info) > 0 {
+							uiStatusBar.SetInfo(info)
+						}
+						app.Draw()
+						return nil
+
+					case 'v', 'r', 'a', 'x', 'y', 'J', 'K', 's': // Edit value/rename/add/delete/duplicate/move/save
+						if editMode == false {
+							return event
+						}
+						if info := editCurrentNode(event.Rune()); len(info) > 0 {
+							uiStatusBar.SetInfo(info)
+						}
+						app.Draw()
+						return nil
+
+					case 'S': // Toggle sorting children by size
+						uiNodeList.SetSortBySize(uiNodeList.IsSortedBySize() == false)
+						uiNodeList.TriggerChanged()
+						app.Draw()
+						return nil
+
+					case 'D': // Size breakdown of the selected node
+						sizeBreakdown.Open(uiNodeList.GetCurrentNode())
+						pages.SwitchToPage(widgets.SizePage)
+						app.SetFocus(sizeBreakdown)
+						app.Draw()
+						return nil
+
+					case 'T': // Table of the selected array
+						node := uiNodeList.GetCurrentNode()
+						array := nodes.EnclosingArray(node)
+						if array == nil {
+							uiStatusBar.SetInfo("The selected node isn't in an array")
+							return nil
+						}
+						tableView.Open(array, node)
+						pages.SwitchToPage(widgets.TablePage)
+						app.SetFocus(tableView)
+						app.Draw()
+						return nil
+
+					case 'O', 'F', 'G': // Sort, filter or group the elements of the selected array
+						if info := arrangeCurrentNode(arrangeKeys[event.Rune()]); len(info) > 0 {
+							uiStatusBar.SetInfo(info)
+						}
+						app.Draw()
+						return nil
+
+					case 'A': // Aggregate the numbers of the selected array
+						info := aggregateCurrentNode(func(array nodes.Node, subPath string, aggregate *nodes.Aggregate) {
+							aggregateView.Open(array, subPath, aggregate)
+							pages.ShowPage(widgets.AggregatePopupPage)
+							app.SetFocus(aggregatePopup)
+						})
+						if len(info) > 0 {
+							uiStatusBar.SetInfo(info)
+						}
+						app.Draw()
+						return nil
+
+					case 'R': // Field profile of the selected array
+						array := nodes.EnclosingArray(uiNodeList.GetCurrentNode())
+						if array == nil {
+							uiStatusBar.SetInfo("The selected node isn't in an array")
+							return nil
+						}
+						if err := fieldProfileList.Open(array); err != nil {
+							uiStatusBar.SetInfo(err.Error())
+							return nil
+						}
+						pages.ShowPage(widgets.FieldProfilePopupPage)
+						app.SetFocus(fieldProfilePopup)
+						app.Draw()
+						return nil
+
+					case 'U': // Duplicate subtrees
+						findDuplicates(func(ignoreKeys []string) {
+							duplicateList.Open(uiNodeList.GetRoot(), ignoreKeys)
+							pages.ShowPage(widgets.DuplicatePopupPage)
+							app.SetFocus(duplicatePopup)
+						})
+						app.Draw()
+						return nil
+
+					case 'V': // Schema violations
+						if currentSchema == nil {
+							uiStatusBar.SetInfo("No schema, use --schema or a $schema key")
+							return nil
+						}
+						violationList.Open(currentTree.Root(), schemaResult.Violations)
+						pages.ShowPage(widgets.ViolationPopupPage)
+						app.SetFocus(violationPopup)
+						app.Draw()
+						return nil
+
+					case 'I': // Infer a schema of the selected node
+						if info := inferCurrentNode(); len(info) > 0 {
+							uiStatusBar.SetInfo(info)
+						}
+						app.Draw()
+						return nil
+
+					case 'u': // Undo
+						uiStatusBar.SetInfo(undo())
+						app.Draw()
+						return nil
+
+					case 'H': // Browse the history of changes
+						historyList.Open(currentTree.History())
+						pages.ShowPage(widgets.HistoryPopupPage)
+						app.SetFocus(historyPopup)
+						app.Draw()
+						return nil
+
+					case 'e': // Open in $EDITOR
+						editor, err := editorCommand(sourcePath, uiNodeList.GetCurrentNode().Position())
+						if err != nil {
+							uiStatusBar.SetInfo(err.Error())
+							app.Draw()
+							return nil
+						}
+						app.Suspend(func() {
+							err = editor.Run()
+						})
+						if err != nil {
+							uiStatusBar.SetInfo(fmt.Sprintf("Editor failed: %s", err))
+						}
 						app.Draw()
 						return nil
 					}
 
+				case tcell.KeyCtrlP: // Fuzzy path finder
+					pathFinder.Open(uiNodeList.GetRoot(), pathSyntax)
+					pages.ShowPage(widgets.PathFinderPopupPage)
+					app.SetFocus(pathFinderPopup)
+					app.Draw()
+					return nil
+
+				case tcell.KeyF1: // Help
+					pages.ShowPage(widgets.HelpPopupPage)
+					app.SetFocus(helpPopup)
+					app.Draw()
+					return nil
+
+				case tcell.KeyBackspace, tcell.KeyBackspace2: // Step back to the previous filter
+					// Filters are always the latest changes, nothing can be edited while they're active
+					if len(filterHistory) == 0 {
+						return nil
+					}
+					undo()
+					app.Draw()
+					return nil
+
+				case tcell.KeyCtrlR: // Redo
+					uiStatusBar.SetInfo(redo())
+					app.Draw()
+					return nil
+
 				case tcell.KeyTab:
 					if uiNodeList.HasFocus() {
 						app.SetFocus(uiOutput)
@@ -181,6 +606,10 @@ func runCommand(_ *cobra.Command, args []string) {
 
 			switch event.Key() {
 			case tcell.KeyEsc:
+				// Leaving the size page selects the node it was at
+				if sizeBreakdown.GetFocusable().HasFocus() {
+					uiNodeList.SelectNode(sizeBreakdown.GetNode())
+				}
 				pages.SwitchToPage(widgets.MainPage)
 				outputPopup.Blur()
 				app.SetFocus(uiNodeList)
@@ -194,23 +623,86 @@ func runCommand(_ *cobra.Command, args []string) {
 	app.Run()
 }
 
+// formatNode formats the node with the selected formatter, or generates code if a language is selected
+func formatNode(node nodes.Node, monochrome bool) string {
+	if len(codeLanguage) > 0 {
+		code := codegen.Generate(nodes.ToRaw(node), nodes.KeyOf(node), codeLanguage)
+		if monochrome {
+			return code
+		}
+		return tview.Escape(code)
+	}
+
+	f := nodes.BuildFormatter(2, monochrome, formatterFileType)
+	node.Format(f, 1)
+	return f.String()
+}
+
+// setDisplayedRoot replaces the root of the node list, matches of the old root are dropped
+func setDisplayedRoot(root nodes.Node) {
+	changedOnly = false
+	uiNodeList.ClearMatches()
+	uiNodeList.SetRoot(root)
+	uiNodeList.SetCurrentItem(0)
+	uiNodeList.TriggerChanged()
+}
+
+// statusInfo joins all non-empty infos for the status bar
+func statusInfo(infos ...string) string {
+	var nonEmpty []string
+	for _, info := range infos {
+		if len(info) > 0 {
+			nonEmpty = append(nonEmpty, info)
+		}
+	}
+	return strings.Join(nonEmpty, "  ")
+}
+
+// sizeInfo shows the size stats of the node for the status bar
+func sizeInfo(node nodes.Node) string {
+	info := nodes.Measure(node).String()
+	if uiNodeList.IsSortedBySize() {
+		info += " (sorted by size)"
+	}
+	return info
+}
+
+// matchInfo formats the match position for the status bar
+func matchInfo(current int, total int) string {
+	switch {
+	case total == 0:
+		return ""
+	case current == 0:
+		return fmt.Sprintf("%d matches", total)
+	default:
+		return fmt.Sprintf("match %d/%d", current, total)
+	}
+}
+
 const askIfBiggerThanMB = 20
 
-func getBytes(args []string) ([]byte, input.FileType, error) {
+// getBytes returns the content, the path of the file (empty if piped) and its filetype
+func getBytes(args []string) ([]byte, string, input.FileType, error) {
 	var fileType input.FileType
 	// Piped in content wins over file
 	stat, _ := os.Stdin.Stat()
 	if (stat.Mode() & os.ModeCharDevice) == 0 {
 		// Read from stdin
 		bytes, err := ioutil.ReadAll(os.Stdin)
-		return bytes, fileType, err
+		return bytes, "", input.SniffFileType(bytes), err
 	}
 
 	if len(args) != 1 {
-		return nil, fileType, errors.New("No file specified and now piped input detected")
+		return nil, "", fileType, errors.New("No file specified and now piped input detected")
 	}
 	path := args[0]
 
+	bytes, fileType, err := readFile(path)
+	return bytes, path, fileType, err
+}
+
+// readFile returns the content of the file and its filetype, big files need to be confirmed
+func readFile(path string) ([]byte, input.FileType, error) {
 	fi, err := os.Stat(path)
 	if err != nil {
 		return nil, input.FileTypeUnknown, err
@@ -228,9 +720,25 @@ func getBytes(args []string) ([]byte, input.FileType, error) {
 
 	bytes, err := ioutil.ReadFile(path)
 	if err != nil {
-		return bytes, fileType, errors.New("Could load file")
+		return bytes, input.FileTypeUnknown, errors.New("Could load file")
+	}
+
+	fileType := input.DetectFileType(path)
+	if fileType == input.FileTypeUnknown {
+		fileType = input.SniffFileType(bytes)
 	}
+	return bytes, fileType, nil
+}
 
-	fileType = input.DetectFileType(path)
-	return bytes, fileType, err
+// loadTree reads and parses a file into a tree
+func loadTree(path string) (*nodes.Tree, error) {
+	bytes, fileType, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := input.Load(fileType, bytes)
+	if err != nil {
+		return nil, err
+	}
+	return nodes.NewTree(fileType, raw, input.Locate(fileType, bytes))
 }
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/benweidig/trex/input"
+	"github.com/benweidig/trex/nodes"
+	"github.com/benweidig/trex/schema"
+)
+
+var (
+	schemaArg string
+
+	// currentSchema is set if the document is validated, the result is updated after every change
+	currentSchema *schema.Schema
+	schemaResult  *schema.Result
+
+	// schemaError is shown in the status bar if the discovered schema couldn't be loaded
+	schemaError string
+)
+
+// setupSchema loads the schema of the --schema flag, or the one referenced by the $schema key.
+// Only an explicitly requested schema has to exist.
+func setupSchema(tree *nodes.Tree, sourcePath string) error {
+	if len(schemaArg) > 0 {
+		s, err := loadSchema(schemaArg)
+		if err != nil {
+			return err
+		}
+		currentSchema = s
+		return nil
+	}
+
+	path, ok := discoverSchema(tree, sourcePath)
+	if ok == false {
+		return nil
+	}
+	s, err := loadSchema(path)
+	if err != nil {
+		schemaError = fmt.Sprintf("Schema %s: %s", path, err.Error())
+		return nil
+	}
+	currentSchema = s
+	return nil
+}
+
+// discoverSchema returns the path of the schema in the $schema key of the root object.
+// Remote schemas can only be used if a local file has the same $id.
+func discoverSchema(tree *nodes.Tree, sourcePath string) (string, bool) {
+	root, ok := nodes.ToRaw(tree.Root()).(map[string]interface{})
+	if ok == false {
+		return "", false
+	}
+	ref, ok := root["$schema"].(string)
+	if ok == false || len(ref) == 0 {
+		return "", false
+	}
+
+	u, err := url.Parse(ref)
+	if err != nil {
+		// Not a URL, like Windows paths
+		return schemaPath(ref, sourcePath), true
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		// The document is a schema itself, we can't validate against the meta-schema
+		if isMetaSchema(u) {
+			return "", false
+		}
+		if path, ok := findSchemaByID(ref, sourcePath); ok {
+			return path, true
+		}
+		schemaError = "Remote schemas aren't supported, use --schema"
+		return "", false
+
+	case "", "file":
+		return schemaPath(u.Path, sourcePath), true
+
+	default:
+		return schemaPath(ref, sourcePath), true
+	}
+}
+
+// schemaPath resolves relative paths against the directory of the document
+func schemaPath(path string, sourcePath string) string {
+	if filepath.IsAbs(path) == false && len(sourcePath) > 0 {
+		return filepath.Join(filepath.Dir(sourcePath), path)
+	}
+	return path
+}
+
+// isMetaSchema checks for the meta-schemas of the JSON Schema drafts
+func isMetaSchema(u *url.URL) bool {
+	return u.Host == "json-schema.org" && (strings.HasPrefix(u.Path, "/draft") || u.Path == "/schema")
+}
+
+// findSchemaByID looks for a JSON/YAML file next to the document with the reference as $id
+func findSchemaByID(ref string, sourcePath string) (string, bool) {
+	dir := "."
+	if len(sourcePath) > 0 {
+		dir = filepath.Dir(sourcePath)
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return "", false
+	}
+
+	for _, file := range files {
+		path := filepath.Join(dir, file.Name())
+		fileType := input.DetectFileType(path)
+		if file.IsDir() || fileType == input.FileTypeUnknown || file.Size() > askIfBiggerThanMB*1024*1024 {
+			continue
+		}
+		if len(sourcePath) > 0 && sameFile(path, sourcePath) {
+			continue
+		}
+
+		bytes, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		raw, err := input.Load(fileType, bytes)
+		if err != nil {
+			continue
+		}
+		if object, ok := schema.Normalize(raw).(map[string]interface{}); ok {
+			if id, ok := object["$id"].(string); ok && strings.TrimSuffix(id, "#") == strings.TrimSuffix(ref, "#") {
+				return path, true
+			}
+		}
+	}
+	return "", false
+}
+
+// sameFile checks if both paths point to the same file
+func sameFile(a string, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+// loadSchema reads and compiles a JSON/YAML schema file
+func loadSchema(path string) (*schema.Schema, error) {
+	bytes, fileType, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := input.Load(fileType, bytes)
+	if err != nil {
+		return nil, err
+	}
+	return schema.Compile(raw)
+}
+
+// validateTree validates the current tree and marks the invalid nodes
+func validateTree() {
+	if currentSchema == nil {
+		return
+	}
+
+	root := currentTree.Root()
+	schemaResult = currentSchema.Validate(nodes.ToRaw(root))
+
+	invalid := make(map[nodes.Node]int)
+	for _, violation := range schemaResult.Violations {
+		if node := nodes.FindByPointer(root, violation.Pointer); node != nil {
+			invalid[node]++
+		}
+	}
+	uiNodeList.SetInvalid(invalid)
+}
+
+// schemaInfo shows the schema description of the node and the count of violations for the status bar
+func schemaInfo(node nodes.Node) string {
+	if currentSchema == nil {
+		return schemaError
+	}
+
+	var description string
+	if len(filterHistory) == 0 {
+		description = schemaResult.Descriptions[nodes.BuildPath(node, nodes.PathSyntaxJSONPointer)]
+	}
+
+	switch count := len(schemaResult.Violations); count {
+	case 0:
+		return statusInfo(description, "valid")
+	case 1:
+		return statusInfo(description, "1 violation")
+	default:
+		return statusInfo(description, fmt.Sprintf("%d violations", count))
+	}
+}
@@ -0,0 +1,270 @@
+// Package codegen generates type declarations (Go structs, TypeScript interfaces, Python
+// dataclasses) from sample values. The shapes of all samples and array elements are merged
+// with an inferred JSON Schema, keys missing in some objects become optional fields.
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/benweidig/trex/schema"
+)
+
+// Language is an enum/constant helper type for the generated languages
+type Language string
+
+const (
+	// LanguageGo generates structs with json/yaml tags
+	LanguageGo Language = "Go"
+
+	// LanguageTypeScript generates interfaces
+	LanguageTypeScript Language = "TypeScript"
+
+	// LanguagePython generates dataclasses
+	LanguagePython Language = "Python"
+)
+
+// Languages contains all languages in display order.
+var Languages = []Language{
+	LanguageGo,
+	LanguageTypeScript,
+	LanguagePython,
+}
+
+// Generate builds the type declarations for the value, the name is used for the root type.
+func Generate(value interface{}, name string, language Language) string {
+	m := newModel()
+	root := m.resolve(schema.Infer(value), typeName(name, "Root"))
+
+	switch language {
+	case LanguageGo:
+		return generateGo(m, root)
+	case LanguageTypeScript:
+		return generateTypeScript(m, root)
+	case LanguagePython:
+		return generatePython(m, root)
+	default:
+		panic("No code generator for '" + string(language) + "'")
+	}
+}
+
+// kind is the simplified type of a value, multiple types become kindAny.
+type kind string
+
+const (
+	kindAny     kind = "any"
+	kindNull    kind = "null"
+	kindString  kind = "string"
+	kindInteger kind = "integer"
+	kindNumber  kind = "number"
+	kindBoolean kind = "boolean"
+	kindArray   kind = "array"
+	kindObject  kind = "object"
+)
+
+type typeRef struct {
+	kind     kind
+	nullable bool
+
+	// name is set for the root type, so non-object roots can be declared as an alias
+	name   string
+	items  *typeRef
+	object *objectType
+}
+
+type objectType struct {
+	name   string
+	fields []field
+}
+
+type field struct {
+	key      string
+	ref      *typeRef
+	required bool
+}
+
+// model collects all object types with unique names, in the order they were found.
+type model struct {
+	objects []*objectType
+	names   map[string]bool
+}
+
+func newModel() *model {
+	return &model{
+		names: make(map[string]bool),
+	}
+}
+
+// resolve converts an inferred schema into a type, nested objects are named after their keys.
+func (m *model) resolve(sch map[string]interface{}, name string) *typeRef {
+	ref := &typeRef{
+		kind: kindAny,
+		name: name,
+	}
+
+	var types []string
+	switch t := sch["type"].(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, name := range t {
+			types = append(types, name.(string))
+		}
+	}
+
+	var nonNull []string
+	for _, t := range types {
+		if t == "null" {
+			ref.nullable = true
+		} else {
+			nonNull = append(nonNull, t)
+		}
+	}
+
+	switch {
+	case len(nonNull) == 0 && ref.nullable:
+		ref.kind = kindNull
+		ref.nullable = false
+	case len(nonNull) == 1:
+		ref.kind = kind(nonNull[0])
+	}
+
+	switch ref.kind {
+	case kindObject:
+		ref.object = m.resolveObject(sch, name)
+	case kindArray:
+		ref.items = &typeRef{kind: kindAny}
+		if items, ok := sch["items"].(map[string]interface{}); ok {
+			ref.items = m.resolve(items, singular(name))
+		}
+	}
+	return ref
+}
+
+func (m *model) resolveObject(sch map[string]interface{}, name string) *objectType {
+	object := &objectType{
+		name: m.uniqueName(name),
+	}
+	m.objects = append(m.objects, object)
+
+	required := make(map[string]bool)
+	if list, ok := sch["required"].([]interface{}); ok {
+		for _, key := range list {
+			required[key.(string)] = true
+		}
+	}
+
+	properties, _ := sch["properties"].(map[string]interface{})
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		property, _ := properties[key].(map[string]interface{})
+		object.fields = append(object.fields, field{
+			key:      key,
+			ref:      m.resolve(property, typeName(key, "Field")),
+			required: required[key],
+		})
+	}
+	return object
+}
+
+func (m *model) uniqueName(name string) string {
+	unique := name
+	for count := 2; m.names[unique]; count++ {
+		unique = fmt.Sprintf("%s%d", name, count)
+	}
+	m.names[unique] = true
+	return unique
+}
+
+// words splits a key into its words, at non-alphanumeric characters and camel case humps.
+func words(key string) []string {
+	var result []string
+	var current []rune
+	runes := []rune(key)
+	for idx, r := range runes {
+		if unicode.IsLetter(r) == false && unicode.IsDigit(r) == false {
+			if len(current) > 0 {
+				result = append(result, string(current))
+				current = nil
+			}
+			continue
+		}
+		hump := idx > 0 && unicode.IsUpper(r) && unicode.IsLower(runes[idx-1])
+		if hump && len(current) > 0 {
+			result = append(result, string(current))
+			current = nil
+		}
+		current = append(current, r)
+	}
+	if len(current) > 0 {
+		result = append(result, string(current))
+	}
+	return result
+}
+
+// initialisms are written in upper case in Go names, like "ID" instead of "Id".
+var initialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// pascalCase converts a key to an exported identifier, the fallback is used for keys without letters.
+func pascalCase(key string, fallback string, useInitialisms bool) string {
+	var builder strings.Builder
+	for _, word := range words(key) {
+		upper := strings.ToUpper(word)
+		if useInitialisms && initialisms[upper] {
+			builder.WriteString(upper)
+			continue
+		}
+		runes := []rune(strings.ToLower(word))
+		runes[0] = unicode.ToUpper(runes[0])
+		builder.WriteString(string(runes))
+	}
+
+	name := builder.String()
+	if len(name) == 0 {
+		return fallback
+	}
+	if unicode.IsDigit([]rune(name)[0]) {
+		name = fallback + name
+	}
+	return name
+}
+
+// typeName is the name of a generated type for a key.
+func typeName(key string, fallback string) string {
+	return pascalCase(key, fallback, false)
+}
+
+// singular is a naive singularization for naming the element types of arrays.
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "ss"):
+		return name
+	case strings.HasSuffix(name, "s") && len(name) > 1:
+		return strings.TrimSuffix(name, "s")
+	default:
+		return name + "Item"
+	}
+}
+
+// isIdentifier reports whether the key can be used as is in the generated code.
+func isIdentifier(key string) bool {
+	for idx, r := range key {
+		if r == '_' || unicode.IsLetter(r) || (idx > 0 && unicode.IsDigit(r)) {
+			continue
+		}
+		return false
+	}
+	return len(key) > 0
+}
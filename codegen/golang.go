@@ -0,0 +1,75 @@
+package codegen
+
+import (
+	"fmt"
+	"go/format"
+	"strings"
+)
+
+func generateGo(m *model, root *typeRef) string {
+	var declarations []string
+	if root.kind != kindObject {
+		declarations = append(declarations, fmt.Sprintf("type %s %s", root.name, goType(root)))
+	}
+
+	for _, object := range m.objects {
+		var builder strings.Builder
+		builder.WriteString(fmt.Sprintf("type %s struct {\n", object.name))
+
+		used := make(map[string]bool)
+		for _, f := range object.fields {
+			name := pascalCase(f.key, "Field", true)
+			for count := 2; used[name]; count++ {
+				name = fmt.Sprintf("%s%d", pascalCase(f.key, "Field", true), count)
+			}
+			used[name] = true
+
+			tag := f.key
+			if f.required == false {
+				tag += ",omitempty"
+			}
+			fieldType := goType(f.ref)
+			if f.required == false && f.ref.kind == kindObject && f.ref.nullable == false {
+				fieldType = "*" + fieldType
+			}
+			builder.WriteString(fmt.Sprintf("\t%s %s `json:%q yaml:%q`\n", name, fieldType, tag, tag))
+		}
+		builder.WriteString("}")
+		declarations = append(declarations, builder.String())
+	}
+
+	source := strings.Join(declarations, "\n\n")
+
+	// gofmt aligns the field types and tags
+	formatted, err := format.Source([]byte(source))
+	if err != nil {
+		return source
+	}
+	return string(formatted)
+}
+
+func goType(ref *typeRef) string {
+	var name string
+	switch ref.kind {
+	case kindString:
+		name = "string"
+	case kindInteger:
+		name = "int64"
+	case kindNumber:
+		name = "float64"
+	case kindBoolean:
+		name = "bool"
+	case kindArray:
+		// Slices are already nil-able
+		return "[]" + goType(ref.items)
+	case kindObject:
+		name = ref.object.name
+	default:
+		return "interface{}"
+	}
+
+	if ref.nullable {
+		return "*" + name
+	}
+	return name
+}
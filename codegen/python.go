@@ -0,0 +1,111 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+const pythonImports = `from dataclasses import dataclass
+from typing import Any, List, Optional`
+
+func generatePython(m *model, root *typeRef) string {
+	declarations := []string{pythonImports}
+
+	// Classes have to be declared before they're used in the module-level alias
+	for idx := len(m.objects) - 1; idx >= 0; idx-- {
+		object := m.objects[idx]
+
+		var builder strings.Builder
+		builder.WriteString(fmt.Sprintf("@dataclass\nclass %s:\n", object.name))
+		if len(object.fields) == 0 {
+			builder.WriteString("    pass\n")
+		}
+
+		// Fields with defaults have to follow the ones without
+		var optional []string
+		used := make(map[string]bool)
+		for _, f := range object.fields {
+			name := snakeCase(f.key)
+			for count := 2; used[name]; count++ {
+				name = fmt.Sprintf("%s_%d", snakeCase(f.key), count)
+			}
+			used[name] = true
+
+			if f.required {
+				builder.WriteString(fmt.Sprintf("    %s: %s\n", name, pythonType(f.ref)))
+				continue
+			}
+			fieldType := pythonType(f.ref)
+			if strings.HasPrefix(fieldType, "Optional[") == false && fieldType != "Any" && fieldType != "None" {
+				fieldType = "Optional[" + fieldType + "]"
+			}
+			optional = append(optional, fmt.Sprintf("    %s: %s = None\n", name, fieldType))
+		}
+		for _, line := range optional {
+			builder.WriteString(line)
+		}
+		declarations = append(declarations, strings.TrimSuffix(builder.String(), "\n"))
+	}
+
+	if root.kind != kindObject {
+		declarations = append(declarations, fmt.Sprintf("%s = %s", root.name, pythonType(root)))
+	}
+
+	return strings.Join(declarations, "\n\n\n")
+}
+
+func pythonType(ref *typeRef) string {
+	var name string
+	switch ref.kind {
+	case kindString:
+		name = "str"
+	case kindInteger:
+		name = "int"
+	case kindNumber:
+		name = "float"
+	case kindBoolean:
+		name = "bool"
+	case kindNull:
+		return "None"
+	case kindArray:
+		name = "List[" + pythonType(ref.items) + "]"
+	case kindObject:
+		name = ref.object.name
+	default:
+		return "Any"
+	}
+
+	if ref.nullable {
+		return "Optional[" + name + "]"
+	}
+	return name
+}
+
+// pythonKeywords can't be used as attribute names.
+var pythonKeywords = map[string]bool{
+	"and": true, "as": true, "assert": true, "async": true, "await": true, "break": true,
+	"class": true, "continue": true, "def": true, "del": true, "elif": true, "else": true,
+	"except": true, "finally": true, "for": true, "from": true, "global": true, "if": true,
+	"import": true, "in": true, "is": true, "lambda": true, "nonlocal": true, "not": true,
+	"or": true, "pass": true, "raise": true, "return": true, "try": true, "while": true,
+	"with": true, "yield": true, "None": true, "True": true, "False": true,
+}
+
+// snakeCase converts a key to a valid Python attribute name.
+func snakeCase(key string) string {
+	parts := words(key)
+	for idx, part := range parts {
+		parts[idx] = strings.ToLower(part)
+	}
+	name := strings.Join(parts, "_")
+	switch {
+	case len(name) == 0:
+		return "field"
+	case name[0] >= '0' && name[0] <= '9':
+		return "field_" + name
+	case pythonKeywords[name]:
+		return name + "_"
+	default:
+		return name
+	}
+}
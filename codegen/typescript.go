@@ -0,0 +1,62 @@
+package codegen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func generateTypeScript(m *model, root *typeRef) string {
+	var declarations []string
+	if root.kind != kindObject {
+		declarations = append(declarations, fmt.Sprintf("export type %s = %s;", root.name, typeScriptType(root)))
+	}
+
+	for _, object := range m.objects {
+		var builder strings.Builder
+		builder.WriteString(fmt.Sprintf("export interface %s {\n", object.name))
+		for _, f := range object.fields {
+			key := f.key
+			if isIdentifier(key) == false {
+				key = strconv.Quote(key)
+			}
+			if f.required == false {
+				key += "?"
+			}
+			builder.WriteString(fmt.Sprintf("  %s: %s;\n", key, typeScriptType(f.ref)))
+		}
+		builder.WriteString("}")
+		declarations = append(declarations, builder.String())
+	}
+
+	return strings.Join(declarations, "\n\n")
+}
+
+func typeScriptType(ref *typeRef) string {
+	var name string
+	switch ref.kind {
+	case kindString:
+		name = "string"
+	case kindInteger, kindNumber:
+		name = "number"
+	case kindBoolean:
+		name = "boolean"
+	case kindNull:
+		name = "null"
+	case kindArray:
+		name = typeScriptType(ref.items)
+		if strings.Contains(name, " ") {
+			name = "(" + name + ")"
+		}
+		name += "[]"
+	case kindObject:
+		name = ref.object.name
+	default:
+		name = "unknown"
+	}
+
+	if ref.nullable {
+		return name + " | null"
+	}
+	return name
+}
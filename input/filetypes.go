@@ -1,6 +1,7 @@
 package input
 
 import (
+	"bytes"
 	"path/filepath"
 	"strings"
 )
@@ -35,3 +36,20 @@ func DetectFileType(path string) FileType {
 		return FileTypeUnknown
 	}
 }
+
+// SniffFileType guesses the filetype of content without a file extension, like piped input.
+// JSON always starts with an object or array, everything else is treated as YAML.
+func SniffFileType(data []byte) FileType {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return FileTypeUnknown
+	}
+
+	switch trimmed[0] {
+	case '[', '{':
+		return FileTypeJSON
+
+	default:
+		return FileTypeYAML
+	}
+}
@@ -3,6 +3,7 @@ package input
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 
 	yaml "gopkg.in/yaml.v2"
 )
@@ -10,6 +11,9 @@ import (
 // Load loads/unmarshals the bytes into the correct map according to the filetype.
 // If no filltype is set it tries to sniff the actual content.
 func Load(fileType FileType, data []byte) (interface{}, error) {
+	if fileType == FileTypeUnknown {
+		fileType = SniffFileType(data)
+	}
 
 	switch fileType {
 	case FileTypeJSON:
@@ -19,13 +23,6 @@ func Load(fileType FileType, data []byte) (interface{}, error) {
 		return loadFromYAML(data)
 	}
 
-	// we couldn't detect the filetype so we have to sniff it
-	first := string(data[0])
-	if first == "[" || first == "{" {
-		return loadFromJSON(data)
-	}
-
-	// TODO: sniff content
 	return nil, errors.New("Failed to load")
 }
 
@@ -38,5 +35,36 @@ func loadFromJSON(data []byte) (interface{}, error) {
 func loadFromYAML(data []byte) (interface{}, error) {
 	var raw interface{}
 	err := yaml.Unmarshal([]byte(data), &raw)
-	return raw, err
+	return normalizeYAML(raw), err
+}
+
+// normalizeYAML converts the values to the types of encoding/json, so YAML and JSON documents
+// are handled alike: numbers are float64 and object keys are strings.
+func normalizeYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		normalized := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			normalized[fmt.Sprint(key)] = normalizeYAML(child)
+		}
+		return normalized
+
+	case []interface{}:
+		for idx, child := range v {
+			v[idx] = normalizeYAML(child)
+		}
+		return v
+
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+
+	default:
+		return value
+	}
 }
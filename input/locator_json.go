@@ -0,0 +1,157 @@
+package input
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// jsonLocator is a minimal JSON scanner that only tracks the positions of values.
+// The data was already unmarshalled successfully, so syntax errors just stop the scan.
+type jsonLocator struct {
+	data      []byte
+	offset    int
+	index     *lineIndex
+	positions Positions
+}
+
+func locateJSON(data []byte) Positions {
+	l := &jsonLocator{
+		data:      data,
+		index:     newLineIndex(data),
+		positions: Positions{},
+	}
+	l.skipWhitespace()
+	l.scanValue("", l.offset)
+	return l.positions
+}
+
+// scanValue scans the value at the current offset. For object members the start
+// is the beginning of the key, so the position includes it.
+func (l *jsonLocator) scanValue(pointer string, start int) bool {
+	if l.offset >= len(l.data) {
+		return false
+	}
+
+	var ok bool
+	switch l.data[l.offset] {
+	case '{':
+		ok = l.scanObject(pointer)
+	case '[':
+		ok = l.scanArray(pointer)
+	case '"':
+		_, ok = l.scanString()
+	default:
+		ok = l.scanLiteral()
+	}
+
+	if ok {
+		l.positions[pointer] = l.index.position(start, l.offset)
+	}
+	return ok
+}
+
+func (l *jsonLocator) scanObject(pointer string) bool {
+	l.offset++
+	l.skipWhitespace()
+	if l.consume('}') {
+		return true
+	}
+
+	for {
+		l.skipWhitespace()
+		keyStart := l.offset
+		key, ok := l.scanString()
+		if ok == false {
+			return false
+		}
+		l.skipWhitespace()
+		if l.consume(':') == false {
+			return false
+		}
+		l.skipWhitespace()
+		if l.scanValue(ChildPointer(pointer, key), keyStart) == false {
+			return false
+		}
+		l.skipWhitespace()
+		if l.consume('}') {
+			return true
+		}
+		if l.consume(',') == false {
+			return false
+		}
+	}
+}
+
+func (l *jsonLocator) scanArray(pointer string) bool {
+	l.offset++
+	l.skipWhitespace()
+	if l.consume(']') {
+		return true
+	}
+
+	for idx := 0; ; idx++ {
+		l.skipWhitespace()
+		if l.scanValue(ChildPointer(pointer, strconv.Itoa(idx)), l.offset) == false {
+			return false
+		}
+		l.skipWhitespace()
+		if l.consume(']') {
+			return true
+		}
+		if l.consume(',') == false {
+			return false
+		}
+	}
+}
+
+func (l *jsonLocator) scanString() (string, bool) {
+	if l.consume('"') == false {
+		return "", false
+	}
+	start := l.offset - 1
+	for l.offset < len(l.data) {
+		switch l.data[l.offset] {
+		case '\\':
+			l.offset += 2
+		case '"':
+			l.offset++
+			var value string
+			err := json.Unmarshal(l.data[start:l.offset], &value)
+			return value, err == nil
+		default:
+			l.offset++
+		}
+	}
+	return "", false
+}
+
+func (l *jsonLocator) scanLiteral() bool {
+	start := l.offset
+	for l.offset < len(l.data) {
+		switch l.data[l.offset] {
+		case ',', '}', ']', ' ', '\t', '\r', '\n':
+			return l.offset > start
+		}
+		l.offset++
+	}
+	return l.offset > start
+}
+
+func (l *jsonLocator) skipWhitespace() {
+	for l.offset < len(l.data) {
+		switch l.data[l.offset] {
+		case ' ', '\t', '\r', '\n':
+			l.offset++
+		default:
+			return
+		}
+	}
+}
+
+func (l *jsonLocator) consume(b byte) bool {
+	if l.offset < len(l.data) && l.data[l.offset] == b {
+		l.offset++
+		return true
+	}
+	return false
+}
@@ -0,0 +1,299 @@
+package input
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// yaml.v2 doesn't expose any positions, so the YAML locator is a line-based heuristic.
+// It understands block mappings, block sequences, block scalars and comments, which covers
+// most real-world documents. Values inside flow collections ({...}, [...]) aren't located
+// individually, only the collection itself.
+
+var yamlKeyRegex = regexp.MustCompile(`^("(?:[^"\\]|\\.)*"|'(?:[^']|'')*'|[^\s#'"\[\]{},&*!|>%@` + "`" + `][^#]*?)\s*:(?:\s+|$)`)
+
+type yamlFrame struct {
+	indent   int
+	pointer  string
+	sequence bool
+	nextIdx  int
+}
+
+type yamlEntry struct {
+	pointer string
+	depth   int
+	start   int
+}
+
+type yamlLocator struct {
+	data      []byte
+	index     *lineIndex
+	positions Positions
+
+	frames  []*yamlFrame
+	entries []yamlEntry
+	pending string
+
+	// Multi-line values are skipped by the indention of their owner or the depth of brackets
+	blockIndent int
+	flowDepth   int
+
+	firstContent   int
+	lastContentEnd int
+}
+
+func locateYAML(data []byte) Positions {
+	l := &yamlLocator{
+		data:         data,
+		index:        newLineIndex(data),
+		positions:    Positions{},
+		blockIndent:  -1,
+		firstContent: -1,
+	}
+	l.scan()
+
+	l.closeEntries(0)
+	if l.firstContent >= 0 {
+		l.positions[""] = l.index.position(l.firstContent, l.lastContentEnd)
+	}
+	return l.positions
+}
+
+func (l *yamlLocator) scan() {
+	lineStarts := l.index.lineStarts
+	for lineIdx, lineStart := range lineStarts {
+		lineEnd := len(l.data)
+		if lineIdx+1 < len(lineStarts) {
+			lineEnd = lineStarts[lineIdx+1] - 1
+		}
+		line := strings.TrimRight(string(l.data[lineStart:lineEnd]), " \t\r")
+		content := strings.TrimLeft(line, " ")
+		indent := len(line) - len(content)
+
+		if l.blockIndent >= 0 {
+			if len(content) == 0 || indent > l.blockIndent {
+				if len(content) > 0 {
+					l.lastContentEnd = lineStart + len(line)
+				}
+				continue
+			}
+			l.blockIndent = -1
+		}
+
+		if l.flowDepth > 0 {
+			l.flowDepth += flowBalance(content)
+			l.lastContentEnd = lineStart + len(line)
+			continue
+		}
+
+		if len(content) == 0 || strings.HasPrefix(content, "#") || strings.HasPrefix(content, "%") {
+			continue
+		}
+
+		if strings.HasPrefix(content, "---") || strings.HasPrefix(content, "...") {
+			// Only the first document is loaded
+			if l.firstContent >= 0 {
+				return
+			}
+			continue
+		}
+
+		if l.firstContent < 0 {
+			l.firstContent = lineStart + indent
+		}
+		l.scanContent(lineStart, indent, content)
+		l.lastContentEnd = lineStart + len(line)
+	}
+}
+
+// scanContent handles a single line, which might contain multiple compact nodes like "- - key: value".
+func (l *yamlLocator) scanContent(lineStart int, column int, content string) {
+	for {
+		if content == "-" || strings.HasPrefix(content, "- ") {
+			frame := l.sequenceFrame(column)
+			pointer := ChildPointer(frame.pointer, strconv.Itoa(frame.nextIdx))
+			frame.nextIdx++
+			l.record(pointer, lineStart+column)
+			l.pending = pointer
+
+			rest := content[1:]
+			value := strings.TrimLeft(rest, " ")
+			if len(value) == 0 || strings.HasPrefix(value, "#") {
+				return
+			}
+			if yamlKeyRegex.MatchString(value) == false {
+				l.scanValue(pointer, column, value)
+				return
+			}
+			column += 1 + len(rest) - len(value)
+			content = value
+			continue
+		}
+
+		match := yamlKeyRegex.FindStringSubmatch(content)
+		if match == nil {
+			// Plain scalars, e.g. continuation lines of multi-line strings
+			return
+		}
+
+		frame := l.mappingFrame(column)
+		pointer := ChildPointer(frame.pointer, unquoteYAMLKey(match[1]))
+		l.record(pointer, lineStart+column)
+		l.scanValue(pointer, column, strings.TrimSpace(content[len(match[0]):]))
+		return
+	}
+}
+
+// scanValue checks if the value will continue on the following lines.
+func (l *yamlLocator) scanValue(pointer string, ownerColumn int, value string) {
+	l.pending = pointer
+
+	// Anchors and tags don't change the structure
+	for strings.HasPrefix(value, "&") || strings.HasPrefix(value, "!") {
+		idx := strings.IndexAny(value, " \t")
+		if idx < 0 {
+			return
+		}
+		value = strings.TrimSpace(value[idx:])
+	}
+
+	if len(value) == 0 {
+		return
+	}
+
+	switch value[0] {
+	case '|', '>':
+		l.blockIndent = ownerColumn
+
+	case '{', '[':
+		l.flowDepth = flowBalance(value)
+	}
+}
+
+func (l *yamlLocator) mappingFrame(column int) *yamlFrame {
+	l.popFrames(column)
+
+	top := l.topFrame()
+	if top != nil && top.indent == column && top.sequence {
+		// A sequence on the same indention as its key ended
+		l.frames = l.frames[:len(l.frames)-1]
+		top = l.topFrame()
+	}
+	if top != nil && top.indent == column {
+		return top
+	}
+
+	return l.pushFrame(column, false)
+}
+
+func (l *yamlLocator) sequenceFrame(column int) *yamlFrame {
+	l.popFrames(column)
+
+	top := l.topFrame()
+	if top != nil && top.indent == column && top.sequence {
+		return top
+	}
+
+	// Sequences might have the same indention as their key, so a new frame is needed
+	// even if the top frame is a mapping with the same indention.
+	return l.pushFrame(column, true)
+}
+
+func (l *yamlLocator) popFrames(column int) {
+	for len(l.frames) > 0 && l.topFrame().indent > column {
+		l.frames = l.frames[:len(l.frames)-1]
+	}
+}
+
+func (l *yamlLocator) topFrame() *yamlFrame {
+	if len(l.frames) == 0 {
+		return nil
+	}
+	return l.frames[len(l.frames)-1]
+}
+
+func (l *yamlLocator) pushFrame(column int, sequence bool) *yamlFrame {
+	pointer := l.pending
+	if len(l.frames) == 0 {
+		pointer = ""
+	}
+	frame := &yamlFrame{
+		indent:   column,
+		pointer:  pointer,
+		sequence: sequence,
+	}
+	l.frames = append(l.frames, frame)
+	return frame
+}
+
+// record remembers the start of a node. The end is only known after the next node
+// with the same or lower depth starts.
+func (l *yamlLocator) record(pointer string, start int) {
+	depth := len(l.frames)
+	l.closeEntries(depth)
+	l.entries = append(l.entries, yamlEntry{
+		pointer: pointer,
+		depth:   depth,
+		start:   start,
+	})
+}
+
+func (l *yamlLocator) closeEntries(depth int) {
+	for len(l.entries) > 0 {
+		last := l.entries[len(l.entries)-1]
+		if last.depth < depth {
+			return
+		}
+		l.entries = l.entries[:len(l.entries)-1]
+
+		end := l.lastContentEnd
+		if end < last.start {
+			// Compact nodes on a single line that hasn't been finished yet
+			end = last.start
+		}
+		if _, exists := l.positions[last.pointer]; exists == false {
+			l.positions[last.pointer] = l.index.position(last.start, end)
+		}
+	}
+}
+
+// flowBalance counts the opened minus closed brackets, ignoring quoted strings.
+func flowBalance(value string) int {
+	var balance int
+	var quote rune
+	for _, r := range value {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return balance
+		case r == '{' || r == '[':
+			balance++
+		case r == '}' || r == ']':
+			balance--
+		}
+	}
+	return balance
+}
+
+func unquoteYAMLKey(key string) string {
+	if len(key) < 2 {
+		return key
+	}
+	switch key[0] {
+	case '"':
+		if unquoted, err := strconv.Unquote(key); err == nil {
+			return unquoted
+		}
+		return key[1 : len(key)-1]
+
+	case '\'':
+		return strings.Replace(key[1:len(key)-1], "''", "'", -1)
+	}
+	return key
+}
@@ -0,0 +1,93 @@
+package input
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Position represents the range of a value in the source document.
+// Lines and columns are 1-based, columns are counted in runes.
+// A zero Position means the location is unknown.
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+
+	EndOffset int
+	EndLine   int
+	EndColumn int
+}
+
+// IsKnown reports whether the position was actually located.
+func (p Position) IsKnown() bool {
+	return p.Line > 0
+}
+
+// Positions maps the JSON Pointer (RFC 6901) of a value to its position.
+type Positions map[string]Position
+
+// ChildPointer appends an escaped reference token to a JSON Pointer.
+func ChildPointer(pointer string, token string) string {
+	token = strings.Replace(token, "~", "~0", -1)
+	token = strings.Replace(token, "/", "~1", -1)
+	return pointer + "/" + token
+}
+
+// Locate tries to find the positions of all values in the data.
+// It's best-effort, so missing or partial positions are no error.
+func Locate(fileType FileType, data []byte) Positions {
+	if fileType == FileTypeUnknown {
+		fileType = SniffFileType(data)
+	}
+
+	switch fileType {
+	case FileTypeJSON:
+		return locateJSON(data)
+
+	case FileTypeYAML:
+		return locateYAML(data)
+	}
+
+	return Positions{}
+}
+
+// lineIndex converts byte offsets to lines/columns.
+type lineIndex struct {
+	data       []byte
+	lineStarts []int
+}
+
+func newLineIndex(data []byte) *lineIndex {
+	idx := &lineIndex{
+		data:       data,
+		lineStarts: []int{0},
+	}
+	for offset, b := range data {
+		if b == '\n' {
+			idx.lineStarts = append(idx.lineStarts, offset+1)
+		}
+	}
+	return idx
+}
+
+func (idx *lineIndex) position(start int, end int) Position {
+	line, column := idx.lineAndColumn(start)
+	endLine, endColumn := idx.lineAndColumn(end)
+	return Position{
+		Offset:    start,
+		Line:      line,
+		Column:    column,
+		EndOffset: end,
+		EndLine:   endLine,
+		EndColumn: endColumn,
+	}
+}
+
+func (idx *lineIndex) lineAndColumn(offset int) (int, int) {
+	line := sort.Search(len(idx.lineStarts), func(i int) bool {
+		return idx.lineStarts[i] > offset
+	})
+	lineStart := idx.lineStarts[line-1]
+	return line, utf8.RuneCount(idx.data[lineStart:offset]) + 1
+}
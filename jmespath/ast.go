@@ -0,0 +1,416 @@
+package jmespath
+
+import (
+	"fmt"
+)
+
+// node is a part of a parsed expression, evaluated against the current value.
+type node interface {
+	eval(value interface{}) (interface{}, error)
+}
+
+type currentNode struct{}
+
+func (currentNode) eval(value interface{}) (interface{}, error) {
+	return value, nil
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n literalNode) eval(value interface{}) (interface{}, error) {
+	return n.value, nil
+}
+
+type fieldNode struct {
+	name string
+}
+
+func (n fieldNode) eval(value interface{}) (interface{}, error) {
+	if object, ok := value.(map[string]interface{}); ok {
+		return object[n.name], nil
+	}
+	return nil, nil
+}
+
+// subexpressionNode applies its nodes one after another, like "a.b.c" or "a[0]".
+type subexpressionNode struct {
+	nodes []node
+}
+
+func (n subexpressionNode) eval(value interface{}) (interface{}, error) {
+	var err error
+	for _, child := range n.nodes {
+		if value, err = child.eval(value); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+type indexNode struct {
+	index int
+}
+
+func (n indexNode) eval(value interface{}) (interface{}, error) {
+	list, ok := value.([]interface{})
+	if ok == false {
+		return nil, nil
+	}
+	index := n.index
+	if index < 0 {
+		index += len(list)
+	}
+	if index < 0 || index >= len(list) {
+		return nil, nil
+	}
+	return list[index], nil
+}
+
+type sliceNode struct {
+	start *int
+	stop  *int
+	step  *int
+}
+
+func (n sliceNode) eval(value interface{}) (interface{}, error) {
+	step := 1
+	if n.step != nil {
+		step = *n.step
+	}
+	if step == 0 {
+		return nil, fmt.Errorf("Invalid value: slice step cannot be 0")
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		var sliced []interface{}
+		for _, idx := range sliceIndices(len(v), n.start, n.stop, step) {
+			sliced = append(sliced, v[idx])
+		}
+		if sliced == nil {
+			sliced = []interface{}{}
+		}
+		return sliced, nil
+
+	case string:
+		runes := []rune(v)
+		var sliced []rune
+		for _, idx := range sliceIndices(len(runes), n.start, n.stop, step) {
+			sliced = append(sliced, runes[idx])
+		}
+		return string(sliced), nil
+	}
+	return nil, nil
+}
+
+// sliceIndices calculates the selected indices with Python semantics.
+func sliceIndices(length int, start *int, stop *int, step int) []int {
+	adjust := func(value *int, fallback int) int {
+		if value == nil {
+			return fallback
+		}
+		idx := *value
+		if idx < 0 {
+			idx += length
+			if idx < 0 {
+				if step < 0 {
+					return -1
+				}
+				return 0
+			}
+		} else if idx >= length {
+			if step < 0 {
+				return length - 1
+			}
+			return length
+		}
+		return idx
+	}
+
+	var indices []int
+	if step > 0 {
+		for idx := adjust(start, 0); idx < adjust(stop, length); idx += step {
+			indices = append(indices, idx)
+		}
+	} else {
+		for idx := adjust(start, length-1); idx > adjust(stop, -1); idx += step {
+			indices = append(indices, idx)
+		}
+	}
+	return indices
+}
+
+// projectionNode applies the right side to every element of the list on the left side,
+// null results are dropped.
+type projectionNode struct {
+	left  node
+	right node
+}
+
+func (n projectionNode) eval(value interface{}) (interface{}, error) {
+	base, err := n.left.eval(value)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := base.([]interface{})
+	if ok == false {
+		return nil, nil
+	}
+	return project(list, n.right)
+}
+
+// valueProjectionNode is like projectionNode, but for the values of an object.
+type valueProjectionNode struct {
+	left  node
+	right node
+}
+
+func (n valueProjectionNode) eval(value interface{}) (interface{}, error) {
+	base, err := n.left.eval(value)
+	if err != nil {
+		return nil, err
+	}
+	object, ok := base.(map[string]interface{})
+	if ok == false {
+		return nil, nil
+	}
+	return project(objectValues(object), n.right)
+}
+
+// filterProjectionNode is a projection that only uses elements matching the condition.
+type filterProjectionNode struct {
+	left      node
+	right     node
+	condition node
+}
+
+func (n filterProjectionNode) eval(value interface{}) (interface{}, error) {
+	base, err := n.left.eval(value)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := base.([]interface{})
+	if ok == false {
+		return nil, nil
+	}
+
+	var matching []interface{}
+	for _, element := range list {
+		result, err := n.condition.eval(element)
+		if err != nil {
+			return nil, err
+		}
+		if isTruthy(result) {
+			matching = append(matching, element)
+		}
+	}
+	return project(matching, n.right)
+}
+
+func project(list []interface{}, right node) (interface{}, error) {
+	collected := []interface{}{}
+	for _, element := range list {
+		result, err := right.eval(element)
+		if err != nil {
+			return nil, err
+		}
+		if result != nil {
+			collected = append(collected, result)
+		}
+	}
+	return collected, nil
+}
+
+// flattenNode merges nested lists one level, like "a[]".
+type flattenNode struct {
+	node node
+}
+
+func (n flattenNode) eval(value interface{}) (interface{}, error) {
+	base, err := n.node.eval(value)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := base.([]interface{})
+	if ok == false {
+		return nil, nil
+	}
+
+	flattened := []interface{}{}
+	for _, element := range list {
+		if nested, isList := element.([]interface{}); isList {
+			flattened = append(flattened, nested...)
+		} else {
+			flattened = append(flattened, element)
+		}
+	}
+	return flattened, nil
+}
+
+type pipeNode struct {
+	left  node
+	right node
+}
+
+func (n pipeNode) eval(value interface{}) (interface{}, error) {
+	left, err := n.left.eval(value)
+	if err != nil {
+		return nil, err
+	}
+	return n.right.eval(left)
+}
+
+type orNode struct {
+	left  node
+	right node
+}
+
+func (n orNode) eval(value interface{}) (interface{}, error) {
+	left, err := n.left.eval(value)
+	if err != nil || isTruthy(left) {
+		return left, err
+	}
+	return n.right.eval(value)
+}
+
+type andNode struct {
+	left  node
+	right node
+}
+
+func (n andNode) eval(value interface{}) (interface{}, error) {
+	left, err := n.left.eval(value)
+	if err != nil || isTruthy(left) == false {
+		return left, err
+	}
+	return n.right.eval(value)
+}
+
+type notNode struct {
+	node node
+}
+
+func (n notNode) eval(value interface{}) (interface{}, error) {
+	result, err := n.node.eval(value)
+	if err != nil {
+		return nil, err
+	}
+	return isTruthy(result) == false, nil
+}
+
+type comparatorNode struct {
+	operator tokenKind
+	left     node
+	right    node
+}
+
+func (n comparatorNode) eval(value interface{}) (interface{}, error) {
+	left, err := n.left.eval(value)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.operator {
+	case tokenEQ:
+		return equals(left, right), nil
+	case tokenNE:
+		return equals(left, right) == false, nil
+	}
+
+	// Ordering comparisons are only defined for numbers
+	leftNumber, leftOk := left.(float64)
+	rightNumber, rightOk := right.(float64)
+	if leftOk == false || rightOk == false {
+		return nil, nil
+	}
+	switch n.operator {
+	case tokenLT:
+		return leftNumber < rightNumber, nil
+	case tokenLTE:
+		return leftNumber <= rightNumber, nil
+	case tokenGT:
+		return leftNumber > rightNumber, nil
+	default:
+		return leftNumber >= rightNumber, nil
+	}
+}
+
+type multiSelectListNode struct {
+	nodes []node
+}
+
+func (n multiSelectListNode) eval(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	list := make([]interface{}, len(n.nodes))
+	for idx, child := range n.nodes {
+		result, err := child.eval(value)
+		if err != nil {
+			return nil, err
+		}
+		list[idx] = result
+	}
+	return list, nil
+}
+
+type keyValuePair struct {
+	key   string
+	value node
+}
+
+type multiSelectHashNode struct {
+	pairs []keyValuePair
+}
+
+func (n multiSelectHashNode) eval(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	object := make(map[string]interface{}, len(n.pairs))
+	for _, pair := range n.pairs {
+		result, err := pair.value.eval(value)
+		if err != nil {
+			return nil, err
+		}
+		object[pair.key] = result
+	}
+	return object, nil
+}
+
+// exprefNode is an expression reference "&expr", it's passed unevaluated to functions.
+type exprefNode struct {
+	node node
+}
+
+func (n exprefNode) eval(value interface{}) (interface{}, error) {
+	return expref{n.node}, nil
+}
+
+// expref is the value of an expression reference.
+type expref struct {
+	node node
+}
+
+type functionNode struct {
+	name string
+	args []node
+}
+
+func (n functionNode) eval(value interface{}) (interface{}, error) {
+	args := make([]interface{}, len(n.args))
+	for idx, arg := range n.args {
+		result, err := arg.eval(value)
+		if err != nil {
+			return nil, err
+		}
+		args[idx] = result
+	}
+	return callFunction(n.name, args)
+}
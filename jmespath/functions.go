@@ -0,0 +1,404 @@
+package jmespath
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type function struct {
+	// arity is the count of arguments, or the minimum if variadic
+	arity    int
+	variadic bool
+	call     func(args []interface{}) (interface{}, error)
+}
+
+var functions = map[string]function{
+	"abs":         {1, false, numberFn(math.Abs)},
+	"ceil":        {1, false, numberFn(math.Ceil)},
+	"floor":       {1, false, numberFn(math.Floor)},
+	"avg":         {1, false, avg},
+	"sum":         {1, false, sum},
+	"contains":    {2, false, contains},
+	"starts_with": {2, false, stringPairFn("starts_with", strings.HasPrefix)},
+	"ends_with":   {2, false, stringPairFn("ends_with", strings.HasSuffix)},
+	"join":        {2, false, join},
+	"keys":        {1, false, keys},
+	"values":      {1, false, values},
+	"length":      {1, false, length},
+	"map":         {2, false, mapFn},
+	"max":         {1, false, extremeFn("max", 1)},
+	"min":         {1, false, extremeFn("min", -1)},
+	"max_by":      {2, false, extremeByFn("max_by", 1)},
+	"min_by":      {2, false, extremeByFn("min_by", -1)},
+	"merge":       {1, true, merge},
+	"not_null":    {1, true, notNull},
+	"reverse":     {1, false, reverse},
+	"sort":        {1, false, sortFn},
+	"sort_by":     {2, false, sortBy},
+	"to_array":    {1, false, toArray},
+	"to_string":   {1, false, toString},
+	"to_number":   {1, false, toNumber},
+	"type": {1, false, func(args []interface{}) (interface{}, error) {
+		return typeName(args[0]), nil
+	}},
+}
+
+// checkFunction validates the name and argument count at parse time.
+func checkFunction(name string, argCount int) error {
+	fn, ok := functions[name]
+	switch {
+	case ok == false:
+		return fmt.Errorf("Unknown function %s()", name)
+	case fn.variadic && argCount < fn.arity:
+		return fmt.Errorf("invalid-arity: %s() takes at least %d arguments, got %d", name, fn.arity, argCount)
+	case fn.variadic == false && argCount != fn.arity:
+		return fmt.Errorf("invalid-arity: %s() takes %d arguments, got %d", name, fn.arity, argCount)
+	}
+	return nil
+}
+
+func callFunction(name string, args []interface{}) (interface{}, error) {
+	if err := checkFunction(name, len(args)); err != nil {
+		return nil, err
+	}
+	return functions[name].call(args)
+}
+
+func invalidType(name string, expected string, actual interface{}) error {
+	return fmt.Errorf("invalid-type: %s() expected %s, got %s", name, expected, typeName(actual))
+}
+
+func numberFn(fn func(float64) float64) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		number, ok := args[0].(float64)
+		if ok == false {
+			return nil, invalidType("number function", "number", args[0])
+		}
+		return fn(number), nil
+	}
+}
+
+func numbers(name string, value interface{}) ([]float64, error) {
+	list, ok := value.([]interface{})
+	if ok == false {
+		return nil, invalidType(name, "array[number]", value)
+	}
+	result := make([]float64, len(list))
+	for idx, element := range list {
+		number, ok := element.(float64)
+		if ok == false {
+			return nil, invalidType(name, "array[number]", element)
+		}
+		result[idx] = number
+	}
+	return result, nil
+}
+
+func sum(args []interface{}) (interface{}, error) {
+	values, err := numbers("sum", args[0])
+	if err != nil {
+		return nil, err
+	}
+	var total float64
+	for _, value := range values {
+		total += value
+	}
+	return total, nil
+}
+
+func avg(args []interface{}) (interface{}, error) {
+	values, err := numbers("avg", args[0])
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+	total, _ := sum(args)
+	return total.(float64) / float64(len(values)), nil
+}
+
+func contains(args []interface{}) (interface{}, error) {
+	switch subject := args[0].(type) {
+	case string:
+		search, ok := args[1].(string)
+		return ok && strings.Contains(subject, search), nil
+	case []interface{}:
+		for _, element := range subject {
+			if equals(element, args[1]) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return nil, invalidType("contains", "array or string", args[0])
+}
+
+func stringPairFn(name string, fn func(string, string) bool) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		for _, arg := range args {
+			if _, ok := arg.(string); ok == false {
+				return nil, invalidType(name, "string", arg)
+			}
+		}
+		return fn(args[0].(string), args[1].(string)), nil
+	}
+}
+
+func join(args []interface{}) (interface{}, error) {
+	glue, ok := args[0].(string)
+	if ok == false {
+		return nil, invalidType("join", "string", args[0])
+	}
+	list, ok := args[1].([]interface{})
+	if ok == false {
+		return nil, invalidType("join", "array[string]", args[1])
+	}
+	parts := make([]string, len(list))
+	for idx, element := range list {
+		if parts[idx], ok = element.(string); ok == false {
+			return nil, invalidType("join", "array[string]", element)
+		}
+	}
+	return strings.Join(parts, glue), nil
+}
+
+func keys(args []interface{}) (interface{}, error) {
+	object, ok := args[0].(map[string]interface{})
+	if ok == false {
+		return nil, invalidType("keys", "object", args[0])
+	}
+	result := []interface{}{}
+	for _, key := range sortedKeys(object) {
+		result = append(result, key)
+	}
+	return result, nil
+}
+
+func values(args []interface{}) (interface{}, error) {
+	object, ok := args[0].(map[string]interface{})
+	if ok == false {
+		return nil, invalidType("values", "object", args[0])
+	}
+	return objectValues(object), nil
+}
+
+func length(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case string:
+		return float64(utf8.RuneCountInString(v)), nil
+	case []interface{}:
+		return float64(len(v)), nil
+	case map[string]interface{}:
+		return float64(len(v)), nil
+	}
+	return nil, invalidType("length", "string, array or object", args[0])
+}
+
+func mapFn(args []interface{}) (interface{}, error) {
+	ref, ok := args[0].(expref)
+	if ok == false {
+		return nil, invalidType("map", "expression", args[0])
+	}
+	list, ok := args[1].([]interface{})
+	if ok == false {
+		return nil, invalidType("map", "array", args[1])
+	}
+	mapped := make([]interface{}, len(list))
+	for idx, element := range list {
+		result, err := ref.node.eval(element)
+		if err != nil {
+			return nil, err
+		}
+		mapped[idx] = result
+	}
+	return mapped, nil
+}
+
+// comparableKeys checks that all keys are either numbers or strings.
+func comparableKeys(name string, keys []interface{}) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	expected := typeName(keys[0])
+	if expected != "number" && expected != "string" {
+		return invalidType(name, "array[number] or array[string]", keys[0])
+	}
+	for _, key := range keys {
+		if typeName(key) != expected {
+			return invalidType(name, "array["+expected+"]", key)
+		}
+	}
+	return nil
+}
+
+func less(a interface{}, b interface{}) bool {
+	if aNumber, ok := a.(float64); ok {
+		return aNumber < b.(float64)
+	}
+	return a.(string) < b.(string)
+}
+
+// keysBy evaluates the expression reference for every element of the list.
+func keysBy(name string, args []interface{}) ([]interface{}, []interface{}, error) {
+	list, ok := args[0].([]interface{})
+	if ok == false {
+		return nil, nil, invalidType(name, "array", args[0])
+	}
+	ref, ok := args[1].(expref)
+	if ok == false {
+		return nil, nil, invalidType(name, "expression", args[1])
+	}
+	keys := make([]interface{}, len(list))
+	for idx, element := range list {
+		key, err := ref.node.eval(element)
+		if err != nil {
+			return nil, nil, err
+		}
+		keys[idx] = key
+	}
+	return list, keys, comparableKeys(name, keys)
+}
+
+// extreme returns the index of the minimum (direction -1) or maximum (direction 1).
+func extreme(keys []interface{}, direction int) int {
+	best := 0
+	for idx := 1; idx < len(keys); idx++ {
+		if (direction > 0 && less(keys[best], keys[idx])) || (direction < 0 && less(keys[idx], keys[best])) {
+			best = idx
+		}
+	}
+	return best
+}
+
+func extremeFn(name string, direction int) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		list, ok := args[0].([]interface{})
+		if ok == false {
+			return nil, invalidType(name, "array", args[0])
+		}
+		if err := comparableKeys(name, list); err != nil || len(list) == 0 {
+			return nil, err
+		}
+		return list[extreme(list, direction)], nil
+	}
+}
+
+func extremeByFn(name string, direction int) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		list, keys, err := keysBy(name, args)
+		if err != nil || len(list) == 0 {
+			return nil, err
+		}
+		return list[extreme(keys, direction)], nil
+	}
+}
+
+func merge(args []interface{}) (interface{}, error) {
+	merged := make(map[string]interface{})
+	for _, arg := range args {
+		object, ok := arg.(map[string]interface{})
+		if ok == false {
+			return nil, invalidType("merge", "object", arg)
+		}
+		for key, value := range object {
+			merged[key] = value
+		}
+	}
+	return merged, nil
+}
+
+func notNull(args []interface{}) (interface{}, error) {
+	for _, arg := range args {
+		if arg != nil {
+			return arg, nil
+		}
+	}
+	return nil, nil
+}
+
+func reverse(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case string:
+		runes := []rune(v)
+		for left, right := 0, len(runes)-1; left < right; left, right = left+1, right-1 {
+			runes[left], runes[right] = runes[right], runes[left]
+		}
+		return string(runes), nil
+	case []interface{}:
+		reversed := make([]interface{}, len(v))
+		for idx, element := range v {
+			reversed[len(v)-1-idx] = element
+		}
+		return reversed, nil
+	}
+	return nil, invalidType("reverse", "array or string", args[0])
+}
+
+func sortFn(args []interface{}) (interface{}, error) {
+	list, ok := args[0].([]interface{})
+	if ok == false {
+		return nil, invalidType("sort", "array", args[0])
+	}
+	if err := comparableKeys("sort", list); err != nil {
+		return nil, err
+	}
+	sorted := append([]interface{}{}, list...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return less(sorted[i], sorted[j])
+	})
+	return sorted, nil
+}
+
+func sortBy(args []interface{}) (interface{}, error) {
+	list, keys, err := keysBy("sort_by", args)
+	if err != nil {
+		return nil, err
+	}
+	indices := make([]int, len(list))
+	for idx := range indices {
+		indices[idx] = idx
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		return less(keys[indices[i]], keys[indices[j]])
+	})
+	sorted := make([]interface{}, len(list))
+	for idx, original := range indices {
+		sorted[idx] = list[original]
+	}
+	return sorted, nil
+}
+
+func toArray(args []interface{}) (interface{}, error) {
+	if list, ok := args[0].([]interface{}); ok {
+		return list, nil
+	}
+	return []interface{}{args[0]}, nil
+}
+
+func toString(args []interface{}) (interface{}, error) {
+	if str, ok := args[0].(string); ok {
+		return str, nil
+	}
+	encoded, err := json.Marshal(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+func toNumber(args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case float64:
+		return v, nil
+	case string:
+		number, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, nil
+		}
+		return number, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,60 @@
+// Package jmespath implements the JMESPath query language (https://jmespath.org/specification.html)
+// for searching JSON-compatible values.
+//
+// Supported are identifiers, sub-expressions, index and slice expressions, projections
+// (list, slice, object, flatten and filter), pipes, multi-select lists and hashes,
+// boolean operators, comparisons, literals, expression references and the builtin functions.
+package jmespath
+
+import "errors"
+
+// Query is a parsed JMESPath expression, it can be evaluated multiple times.
+type Query struct {
+	expression string
+	root       node
+}
+
+// Parse parses a JMESPath expression.
+func Parse(expression string) (*Query, error) {
+	p, err := newParser(expression)
+	if err != nil {
+		return nil, err
+	}
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return &Query{
+		expression: expression,
+		root:       root,
+	}, nil
+}
+
+// String returns the original expression.
+func (q *Query) String() string {
+	return q.expression
+}
+
+// Search runs the query against the input and returns the result.
+// The input must only consist of JSON-compatible types (map[string]interface{},
+// []interface{}, string, float64, bool and nil), and so does the result, results containing
+// expression references are an error.
+func (q *Query) Search(input interface{}) (interface{}, error) {
+	result, err := q.root.eval(input)
+	if err != nil {
+		return nil, err
+	}
+	if containsExpref(result) {
+		return nil, errors.New("Expression references can only be passed to functions")
+	}
+	return result, nil
+}
+
+// Search is a convenience function to parse and evaluate an expression in one step.
+func Search(expression string, input interface{}) (interface{}, error) {
+	q, err := Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+	return q.Search(input)
+}
@@ -0,0 +1,210 @@
+package jmespath
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenUnquotedIdentifier
+	tokenQuotedIdentifier
+	tokenNumber
+	tokenLiteral
+	tokenDot
+	tokenStar
+	tokenFlatten
+	tokenFilter
+	tokenLBracket
+	tokenRBracket
+	tokenLBrace
+	tokenRBrace
+	tokenLParen
+	tokenRParen
+	tokenComma
+	tokenColon
+	tokenCurrent
+	tokenExpref
+	tokenPipe
+	tokenOr
+	tokenAnd
+	tokenNot
+	tokenEQ
+	tokenNE
+	tokenLT
+	tokenLTE
+	tokenGT
+	tokenGTE
+)
+
+type token struct {
+	kind    tokenKind
+	text    string
+	literal interface{}
+	pos     int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of input"
+	case tokenLiteral:
+		return "literal"
+	default:
+		return "'" + t.text + "'"
+	}
+}
+
+// simpleTokens are the single characters that can't start any longer token.
+var simpleTokens = map[byte]tokenKind{
+	'.': tokenDot,
+	'*': tokenStar,
+	']': tokenRBracket,
+	'{': tokenLBrace,
+	'}': tokenRBrace,
+	'(': tokenLParen,
+	')': tokenRParen,
+	',': tokenComma,
+	':': tokenColon,
+	'@': tokenCurrent,
+}
+
+// pairTokens are tokens that are different if followed by a specific character, like "|" and "||".
+var pairTokens = map[byte]struct {
+	next   byte
+	single tokenKind
+	double tokenKind
+}{
+	'[': {']', tokenLBracket, tokenFlatten},
+	'|': {'|', tokenPipe, tokenOr},
+	'&': {'&', tokenExpref, tokenAnd},
+	'!': {'=', tokenNot, tokenNE},
+	'<': {'=', tokenLT, tokenLTE},
+	'>': {'=', tokenGT, tokenGTE},
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	pos := 0
+
+	for pos < len(input) {
+		c := input[pos]
+		start := pos
+
+		if kind, ok := simpleTokens[c]; ok {
+			tokens = append(tokens, token{kind: kind, text: string(c), pos: start})
+			pos++
+			continue
+		}
+
+		if pair, ok := pairTokens[c]; ok {
+			switch {
+			case c == '[' && pos+1 < len(input) && input[pos+1] == '?':
+				tokens = append(tokens, token{kind: tokenFilter, text: "[?", pos: start})
+				pos += 2
+			case pos+1 < len(input) && input[pos+1] == pair.next:
+				tokens = append(tokens, token{kind: pair.double, text: input[pos : pos+2], pos: start})
+				pos += 2
+			default:
+				tokens = append(tokens, token{kind: pair.single, text: string(c), pos: start})
+				pos++
+			}
+			continue
+		}
+
+		switch {
+		case unicode.IsSpace(rune(c)):
+			pos++
+
+		case isIdentStart(c):
+			for pos < len(input) && isIdentPart(input[pos]) {
+				pos++
+			}
+			tokens = append(tokens, token{kind: tokenUnquotedIdentifier, text: input[start:pos], pos: start})
+
+		case c == '-' || (c >= '0' && c <= '9'):
+			pos++
+			for pos < len(input) && input[pos] >= '0' && input[pos] <= '9' {
+				pos++
+			}
+			number, err := strconv.Atoi(input[start:pos])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid number '%s' at position %d", input[start:pos], start+1)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: input[start:pos], literal: number, pos: start})
+
+		case c == '=':
+			if pos+1 >= len(input) || input[pos+1] != '=' {
+				return nil, fmt.Errorf("Unexpected '=' at position %d, did you mean '=='?", start+1)
+			}
+			tokens = append(tokens, token{kind: tokenEQ, text: "==", pos: start})
+			pos += 2
+
+		case c == '"':
+			end, err := findClosing(input, pos, '"')
+			if err != nil {
+				return nil, err
+			}
+			var name string
+			if err := json.Unmarshal([]byte(input[pos:end]), &name); err != nil {
+				return nil, fmt.Errorf("Invalid quoted identifier at position %d", start+1)
+			}
+			tokens = append(tokens, token{kind: tokenQuotedIdentifier, text: name, pos: start})
+			pos = end
+
+		case c == '\'':
+			end, err := findClosing(input, pos, '\'')
+			if err != nil {
+				return nil, err
+			}
+			raw := strings.Replace(input[pos+1:end-1], `\'`, `'`, -1)
+			tokens = append(tokens, token{kind: tokenLiteral, text: input[pos:end], literal: raw, pos: start})
+			pos = end
+
+		case c == '`':
+			end, err := findClosing(input, pos, '`')
+			if err != nil {
+				return nil, err
+			}
+			var literal interface{}
+			raw := strings.Replace(input[pos+1:end-1], "\\`", "`", -1)
+			if err := json.Unmarshal([]byte(raw), &literal); err != nil {
+				return nil, fmt.Errorf("Invalid JSON literal at position %d: %s", start+1, err.Error())
+			}
+			tokens = append(tokens, token{kind: tokenLiteral, text: input[pos:end], literal: literal, pos: start})
+			pos = end
+
+		default:
+			return nil, fmt.Errorf("Unexpected character '%c' at position %d", c, pos+1)
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(input)})
+	return tokens, nil
+}
+
+// findClosing returns the position after the closing quote, backslashes escape the next character.
+func findClosing(input string, start int, quote byte) (int, error) {
+	for pos := start + 1; pos < len(input); pos++ {
+		switch input[pos] {
+		case '\\':
+			pos++
+		case quote:
+			return pos + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("Unterminated %c at position %d", quote, start+1)
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
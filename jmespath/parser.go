@@ -0,0 +1,435 @@
+package jmespath
+
+import (
+	"fmt"
+)
+
+// bindingPowers of the tokens for the top down operator precedence parser, like in the
+// reference implementation. Tokens with a binding power below projectionStop end a projection.
+var bindingPowers = map[tokenKind]int{
+	tokenPipe:     1,
+	tokenOr:       2,
+	tokenAnd:      3,
+	tokenEQ:       5,
+	tokenNE:       5,
+	tokenLT:       5,
+	tokenLTE:      5,
+	tokenGT:       5,
+	tokenGTE:      5,
+	tokenFlatten:  9,
+	tokenStar:     20,
+	tokenFilter:   21,
+	tokenDot:      40,
+	tokenNot:      45,
+	tokenLBrace:   50,
+	tokenLBracket: 55,
+	tokenLParen:   60,
+}
+
+const projectionStop = 10
+
+// parser is a top down operator precedence (Pratt) parser.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func newParser(expression string) (*parser, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	return &parser{
+		tokens: tokens,
+	}, nil
+}
+
+func (p *parser) parse() (node, error) {
+	if p.current().kind == tokenEOF {
+		return nil, p.errorf(p.current(), "Empty expression")
+	}
+
+	root, err := p.parseExpression(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.current(); t.kind != tokenEOF {
+		return nil, p.errorf(t, "Unexpected %s", t)
+	}
+	return root, nil
+}
+
+func (p *parser) parseExpression(bindingPower int) (node, error) {
+	t := p.advance()
+	left, err := p.nud(t)
+	if err != nil {
+		return nil, err
+	}
+
+	for bindingPower < bindingPowers[p.current().kind] {
+		t := p.advance()
+		if left, err = p.led(t, left); err != nil {
+			return nil, err
+		}
+	}
+	return left, nil
+}
+
+// nud handles tokens at the beginning of an expression.
+func (p *parser) nud(t token) (node, error) {
+	switch t.kind {
+	case tokenLiteral:
+		return literalNode{t.literal}, nil
+
+	case tokenUnquotedIdentifier:
+		return fieldNode{t.text}, nil
+
+	case tokenQuotedIdentifier:
+		if p.current().kind == tokenLParen {
+			return nil, p.errorf(p.current(), "Quoted identifiers can't be used as function names")
+		}
+		return fieldNode{t.text}, nil
+
+	case tokenStar:
+		if p.current().kind == tokenRBracket {
+			return valueProjectionNode{currentNode{}, currentNode{}}, nil
+		}
+		right, err := p.parseProjectionRHS(bindingPowers[tokenStar])
+		if err != nil {
+			return nil, err
+		}
+		return valueProjectionNode{currentNode{}, right}, nil
+
+	case tokenFilter:
+		return p.parseFilter(currentNode{})
+
+	case tokenLBrace:
+		return p.parseMultiSelectHash()
+
+	case tokenLParen:
+		inner, err := p.parseExpression(0)
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(tokenRParen)
+
+	case tokenFlatten:
+		right, err := p.parseProjectionRHS(bindingPowers[tokenFlatten])
+		if err != nil {
+			return nil, err
+		}
+		return projectionNode{flattenNode{currentNode{}}, right}, nil
+
+	case tokenNot:
+		inner, err := p.parseExpression(bindingPowers[tokenNot])
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner}, nil
+
+	case tokenLBracket:
+		switch {
+		case p.current().kind == tokenNumber || p.current().kind == tokenColon:
+			right, err := p.parseIndex()
+			if err != nil {
+				return nil, err
+			}
+			return p.projectIfSlice(currentNode{}, right)
+
+		case p.current().kind == tokenStar && p.peek(1).kind == tokenRBracket:
+			p.advance()
+			p.advance()
+			right, err := p.parseProjectionRHS(bindingPowers[tokenStar])
+			if err != nil {
+				return nil, err
+			}
+			return projectionNode{currentNode{}, right}, nil
+
+		default:
+			return p.parseMultiSelectList()
+		}
+
+	case tokenCurrent:
+		return currentNode{}, nil
+
+	case tokenExpref:
+		inner, err := p.parseExpression(0)
+		if err != nil {
+			return nil, err
+		}
+		return exprefNode{inner}, nil
+	}
+
+	return nil, p.errorf(t, "Unexpected %s", t)
+}
+
+// led handles tokens following a left expression.
+func (p *parser) led(t token, left node) (node, error) {
+	switch t.kind {
+	case tokenDot:
+		if p.current().kind == tokenStar {
+			p.advance()
+			right, err := p.parseProjectionRHS(bindingPowers[tokenDot])
+			if err != nil {
+				return nil, err
+			}
+			return valueProjectionNode{left, right}, nil
+		}
+		right, err := p.parseDotRHS(bindingPowers[tokenDot])
+		if err != nil {
+			return nil, err
+		}
+		if sub, ok := left.(subexpressionNode); ok {
+			return subexpressionNode{append(append([]node{}, sub.nodes...), right)}, nil
+		}
+		return subexpressionNode{[]node{left, right}}, nil
+
+	case tokenPipe:
+		right, err := p.parseExpression(bindingPowers[tokenPipe])
+		if err != nil {
+			return nil, err
+		}
+		return pipeNode{left, right}, nil
+
+	case tokenOr:
+		right, err := p.parseExpression(bindingPowers[tokenOr])
+		if err != nil {
+			return nil, err
+		}
+		return orNode{left, right}, nil
+
+	case tokenAnd:
+		right, err := p.parseExpression(bindingPowers[tokenAnd])
+		if err != nil {
+			return nil, err
+		}
+		return andNode{left, right}, nil
+
+	case tokenLParen:
+		field, ok := left.(fieldNode)
+		if ok == false {
+			return nil, p.errorf(t, "Invalid function call")
+		}
+		var args []node
+		for p.current().kind != tokenRParen {
+			arg, err := p.parseExpression(0)
+			if err != nil {
+				return nil, err
+			}
+			if p.current().kind == tokenComma {
+				p.advance()
+			}
+			args = append(args, arg)
+		}
+		p.advance()
+		if err := checkFunction(field.name, len(args)); err != nil {
+			return nil, p.errorf(t, "%s", err.Error())
+		}
+		return functionNode{field.name, args}, nil
+
+	case tokenFilter:
+		return p.parseFilter(left)
+
+	case tokenEQ, tokenNE, tokenLT, tokenLTE, tokenGT, tokenGTE:
+		right, err := p.parseExpression(bindingPowers[t.kind])
+		if err != nil {
+			return nil, err
+		}
+		return comparatorNode{t.kind, left, right}, nil
+
+	case tokenFlatten:
+		right, err := p.parseProjectionRHS(bindingPowers[tokenFlatten])
+		if err != nil {
+			return nil, err
+		}
+		return projectionNode{flattenNode{left}, right}, nil
+
+	case tokenLBracket:
+		if p.current().kind == tokenNumber || p.current().kind == tokenColon {
+			right, err := p.parseIndex()
+			if err != nil {
+				return nil, err
+			}
+			return p.projectIfSlice(left, right)
+		}
+		if err := p.expect(tokenStar); err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRBracket); err != nil {
+			return nil, err
+		}
+		right, err := p.parseProjectionRHS(bindingPowers[tokenStar])
+		if err != nil {
+			return nil, err
+		}
+		return projectionNode{left, right}, nil
+	}
+
+	return nil, p.errorf(t, "Unexpected %s", t)
+}
+
+func (p *parser) parseFilter(left node) (node, error) {
+	condition, err := p.parseExpression(0)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(tokenRBracket); err != nil {
+		return nil, err
+	}
+
+	var right node = currentNode{}
+	if p.current().kind != tokenFlatten {
+		if right, err = p.parseProjectionRHS(bindingPowers[tokenFilter]); err != nil {
+			return nil, err
+		}
+	}
+	return filterProjectionNode{left, right, condition}, nil
+}
+
+// parseIndex parses an index "[0]" or slice "[1:2:3]" after the opening bracket.
+func (p *parser) parseIndex() (node, error) {
+	if p.current().kind == tokenColon || p.peek(1).kind == tokenColon {
+		return p.parseSlice()
+	}
+	t := p.advance()
+	return indexNode{t.literal.(int)}, p.expect(tokenRBracket)
+}
+
+func (p *parser) parseSlice() (node, error) {
+	var parts [3]*int
+	idx := 0
+	for p.current().kind != tokenRBracket {
+		t := p.advance()
+		switch {
+		case t.kind == tokenColon && idx < 2:
+			idx++
+		case t.kind == tokenNumber && parts[idx] == nil:
+			number := t.literal.(int)
+			parts[idx] = &number
+		default:
+			return nil, p.errorf(t, "Unexpected %s in slice", t)
+		}
+	}
+	p.advance()
+	return sliceNode{parts[0], parts[1], parts[2]}, nil
+}
+
+// projectIfSlice creates a projection for slices, "a[1:]" projects like "a[*]".
+func (p *parser) projectIfSlice(left node, right node) (node, error) {
+	indexed := subexpressionNode{[]node{left, right}}
+	if _, isSlice := right.(sliceNode); isSlice == false {
+		return indexed, nil
+	}
+	rhs, err := p.parseProjectionRHS(bindingPowers[tokenStar])
+	if err != nil {
+		return nil, err
+	}
+	return projectionNode{indexed, rhs}, nil
+}
+
+func (p *parser) parseMultiSelectList() (node, error) {
+	var nodes []node
+	for {
+		element, err := p.parseExpression(0)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, element)
+		if p.current().kind == tokenRBracket {
+			break
+		}
+		if err := p.expect(tokenComma); err != nil {
+			return nil, err
+		}
+	}
+	p.advance()
+	return multiSelectListNode{nodes}, nil
+}
+
+func (p *parser) parseMultiSelectHash() (node, error) {
+	var pairs []keyValuePair
+	for {
+		key := p.advance()
+		if key.kind != tokenUnquotedIdentifier && key.kind != tokenQuotedIdentifier {
+			return nil, p.errorf(key, "Expected a key but got %s", key)
+		}
+		if err := p.expect(tokenColon); err != nil {
+			return nil, err
+		}
+		value, err := p.parseExpression(0)
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, keyValuePair{key.text, value})
+
+		if p.current().kind == tokenRBrace {
+			p.advance()
+			break
+		}
+		if err := p.expect(tokenComma); err != nil {
+			return nil, err
+		}
+	}
+	return multiSelectHashNode{pairs}, nil
+}
+
+// parseProjectionRHS parses the expression applied to every element of a projection.
+func (p *parser) parseProjectionRHS(bindingPower int) (node, error) {
+	switch t := p.current(); {
+	case bindingPowers[t.kind] < projectionStop:
+		return currentNode{}, nil
+	case t.kind == tokenLBracket || t.kind == tokenFilter:
+		return p.parseExpression(bindingPower)
+	case t.kind == tokenDot:
+		p.advance()
+		return p.parseDotRHS(bindingPower)
+	default:
+		return nil, p.errorf(t, "Unexpected %s", t)
+	}
+}
+
+func (p *parser) parseDotRHS(bindingPower int) (node, error) {
+	switch t := p.current(); t.kind {
+	case tokenUnquotedIdentifier, tokenQuotedIdentifier, tokenStar:
+		return p.parseExpression(bindingPower)
+	case tokenLBracket:
+		p.advance()
+		return p.parseMultiSelectList()
+	case tokenLBrace:
+		p.advance()
+		return p.parseMultiSelectHash()
+	default:
+		return nil, p.errorf(t, "Expected an identifier, '[' or '{' after '.', got %s", t)
+	}
+}
+
+func (p *parser) current() token {
+	return p.peek(0)
+}
+
+func (p *parser) peek(offset int) token {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+offset]
+}
+
+func (p *parser) advance() token {
+	t := p.current()
+	if p.pos < len(p.tokens)-1 {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(kind tokenKind) error {
+	t := p.advance()
+	if t.kind != kind {
+		return p.errorf(t, "Unexpected %s", t)
+	}
+	return nil
+}
+
+func (p *parser) errorf(t token, format string, args ...interface{}) error {
+	return fmt.Errorf("Syntax error at position %d: %s", t.pos+1, fmt.Sprintf(format, args...))
+}
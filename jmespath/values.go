@@ -0,0 +1,86 @@
+package jmespath
+
+import (
+	"reflect"
+	"sort"
+)
+
+// isTruthy follows the JMESPath definition: false, null and empty values are false.
+func isTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return len(v) > 0
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	}
+	return true
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case expref:
+		return "expref"
+	}
+	return "unknown"
+}
+
+// containsExpref reports whether the value isn't JSON-compatible, because it contains an expression reference.
+func containsExpref(value interface{}) bool {
+	switch v := value.(type) {
+	case expref:
+		return true
+	case []interface{}:
+		for _, item := range v {
+			if containsExpref(item) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			if containsExpref(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func equals(a interface{}, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// objectValues returns the values of an object, sorted by their keys to be deterministic.
+func objectValues(object map[string]interface{}) []interface{} {
+	values := make([]interface{}, 0, len(object))
+	for _, key := range sortedKeys(object) {
+		values = append(values, object[key])
+	}
+	return values
+}
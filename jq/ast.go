@@ -0,0 +1,674 @@
+package jq
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// expr is a node of the parsed expression. Every expression is a generator producing
+// zero or more outputs for a single input.
+type expr interface {
+	eval(e *env, input interface{}) ([]interface{}, error)
+}
+
+// env is a linked list of variable bindings.
+type env struct {
+	name   string
+	value  interface{}
+	parent *env
+}
+
+func (e *env) bind(name string, value interface{}) *env {
+	return &env{
+		name:   name,
+		value:  value,
+		parent: e,
+	}
+}
+
+func (e *env) lookup(name string) (interface{}, bool) {
+	for current := e; current != nil; current = current.parent {
+		if current.name == name {
+			return current.value, true
+		}
+	}
+	return nil, false
+}
+
+// limitedExpr is implemented by generators that can stop early, so limit and first don't
+// evaluate more outputs than they need.
+type limitedExpr interface {
+	evalLimited(e *env, input interface{}, n int) ([]interface{}, error)
+}
+
+// evalLimited returns at most the first n outputs of the expression.
+func evalLimited(x expr, e *env, input interface{}, n int) ([]interface{}, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	if limited, ok := x.(limitedExpr); ok {
+		return limited.evalLimited(e, input, n)
+	}
+	outputs, err := x.eval(e, input)
+	return truncate(outputs, n), err
+}
+
+func truncate(outputs []interface{}, n int) []interface{} {
+	if len(outputs) > n {
+		return outputs[:n]
+	}
+	return outputs
+}
+
+type identityExpr struct{}
+
+func (x identityExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	return []interface{}{input}, nil
+}
+
+type recurseExpr struct{}
+
+func (x recurseExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	return appendRecursive(nil, input), nil
+}
+
+func appendRecursive(outputs []interface{}, value interface{}) []interface{} {
+	outputs = append(outputs, value)
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			outputs = appendRecursive(outputs, item)
+		}
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			outputs = appendRecursive(outputs, v[key])
+		}
+	}
+	return outputs
+}
+
+type literalExpr struct {
+	value interface{}
+}
+
+func (x literalExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	return []interface{}{x.value}, nil
+}
+
+type stringExpr struct {
+	parts []stringPart
+}
+
+func (x stringExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	results := []string{""}
+	for _, part := range x.parts {
+		if part.expr == nil {
+			for idx := range results {
+				results[idx] += part.literal
+			}
+			continue
+		}
+
+		outputs, err := part.expr.eval(e, input)
+		if err != nil {
+			return nil, err
+		}
+		var next []string
+		for _, prefix := range results {
+			for _, output := range outputs {
+				str, isString := output.(string)
+				if isString == false {
+					str = toJSON(output)
+				}
+				next = append(next, prefix+str)
+			}
+		}
+		results = next
+	}
+
+	outputs := make([]interface{}, len(results))
+	for idx, result := range results {
+		outputs[idx] = result
+	}
+	return outputs, nil
+}
+
+type indexExpr struct {
+	target expr
+	index  expr
+}
+
+func (x indexExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	targets, err := x.target.eval(e, input)
+	if err != nil {
+		return nil, err
+	}
+	// The index is evaluated against the original input, like ".[.key]"
+	indices, err := x.index.eval(e, input)
+	if err != nil {
+		return nil, err
+	}
+
+	var outputs []interface{}
+	for _, target := range targets {
+		for _, index := range indices {
+			value, err := indexValue(target, index)
+			if err != nil {
+				return nil, err
+			}
+			outputs = append(outputs, value)
+		}
+	}
+	return outputs, nil
+}
+
+type sliceExpr struct {
+	target expr
+	from   expr
+	to     expr
+}
+
+func (x sliceExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	targets, err := x.target.eval(e, input)
+	if err != nil {
+		return nil, err
+	}
+
+	bound := func(bound expr) ([]interface{}, error) {
+		if bound == nil {
+			return []interface{}{nil}, nil
+		}
+		return bound.eval(e, input)
+	}
+	froms, err := bound(x.from)
+	if err != nil {
+		return nil, err
+	}
+	tos, err := bound(x.to)
+	if err != nil {
+		return nil, err
+	}
+
+	var outputs []interface{}
+	for _, target := range targets {
+		for _, to := range tos {
+			for _, from := range froms {
+				value, err := sliceValue(target, from, to)
+				if err != nil {
+					return nil, err
+				}
+				outputs = append(outputs, value)
+			}
+		}
+	}
+	return outputs, nil
+}
+
+type iterateExpr struct {
+	target expr
+}
+
+func (x iterateExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	targets, err := x.target.eval(e, input)
+	if err != nil {
+		return nil, err
+	}
+
+	var outputs []interface{}
+	for _, target := range targets {
+		switch t := target.(type) {
+		case []interface{}:
+			outputs = append(outputs, t...)
+		case map[string]interface{}:
+			for _, key := range sortedKeys(t) {
+				outputs = append(outputs, t[key])
+			}
+		default:
+			return nil, fmt.Errorf("Cannot iterate over %s", describe(target))
+		}
+	}
+	return outputs, nil
+}
+
+type pipeExpr struct {
+	left  expr
+	right expr
+}
+
+func (x pipeExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	lefts, err := x.left.eval(e, input)
+	if err != nil {
+		return nil, err
+	}
+
+	var outputs []interface{}
+	for _, left := range lefts {
+		rights, err := x.right.eval(e, left)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, rights...)
+	}
+	return outputs, nil
+}
+
+// evalLimited can't know how many inputs the right side needs, it might filter most of them.
+// So the left side is evaluated with a doubling limit until there are enough outputs.
+func (x pipeExpr) evalLimited(e *env, input interface{}, n int) ([]interface{}, error) {
+	for leftLimit := n; ; leftLimit *= 2 {
+		lefts, err := evalLimited(x.left, e, input, leftLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		var outputs []interface{}
+		for _, left := range lefts {
+			if len(outputs) >= n {
+				break
+			}
+			rights, err := evalLimited(x.right, e, left, n-len(outputs))
+			if err != nil {
+				return nil, err
+			}
+			outputs = append(outputs, rights...)
+		}
+		if len(outputs) >= n || len(lefts) < leftLimit || leftLimit >= math.MaxInt32/2 {
+			return outputs, nil
+		}
+	}
+}
+
+type commaExpr struct {
+	left  expr
+	right expr
+}
+
+func (x commaExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	lefts, err := x.left.eval(e, input)
+	if err != nil {
+		return nil, err
+	}
+	rights, err := x.right.eval(e, input)
+	if err != nil {
+		return nil, err
+	}
+	return append(lefts, rights...), nil
+}
+
+func (x commaExpr) evalLimited(e *env, input interface{}, n int) ([]interface{}, error) {
+	lefts, err := evalLimited(x.left, e, input, n)
+	if err != nil || len(lefts) >= n {
+		return lefts, err
+	}
+	rights, err := evalLimited(x.right, e, input, n-len(lefts))
+	if err != nil {
+		return nil, err
+	}
+	return append(lefts, rights...), nil
+}
+
+type negateExpr struct {
+	expr expr
+}
+
+func (x negateExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	values, err := x.expr.eval(e, input)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]interface{}, len(values))
+	for idx, value := range values {
+		number, isNumber := value.(float64)
+		if isNumber == false {
+			return nil, fmt.Errorf("%s cannot be negated", describe(value))
+		}
+		outputs[idx] = -number
+	}
+	return outputs, nil
+}
+
+type binaryExpr struct {
+	op    string
+	left  expr
+	right expr
+}
+
+func (x binaryExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	lefts, err := x.left.eval(e, input)
+	if err != nil {
+		return nil, err
+	}
+	rights, err := x.right.eval(e, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// jq iterates the right side in the outer loop
+	var outputs []interface{}
+	for _, right := range rights {
+		for _, left := range lefts {
+			value, err := binaryOp(x.op, left, right)
+			if err != nil {
+				return nil, err
+			}
+			outputs = append(outputs, value)
+		}
+	}
+	return outputs, nil
+}
+
+type andExpr struct {
+	left  expr
+	right expr
+}
+
+func (x andExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	lefts, err := x.left.eval(e, input)
+	if err != nil {
+		return nil, err
+	}
+
+	var outputs []interface{}
+	for _, left := range lefts {
+		if isTruthy(left) == false {
+			outputs = append(outputs, false)
+			continue
+		}
+		rights, err := x.right.eval(e, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, right := range rights {
+			outputs = append(outputs, isTruthy(right))
+		}
+	}
+	return outputs, nil
+}
+
+type orExpr struct {
+	left  expr
+	right expr
+}
+
+func (x orExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	lefts, err := x.left.eval(e, input)
+	if err != nil {
+		return nil, err
+	}
+
+	var outputs []interface{}
+	for _, left := range lefts {
+		if isTruthy(left) {
+			outputs = append(outputs, true)
+			continue
+		}
+		rights, err := x.right.eval(e, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, right := range rights {
+			outputs = append(outputs, isTruthy(right))
+		}
+	}
+	return outputs, nil
+}
+
+// alternativeExpr is "a // b", returning all truthy outputs of a, or b if there are none.
+type alternativeExpr struct {
+	left  expr
+	right expr
+}
+
+func (x alternativeExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	// Errors on the left side are treated like no output
+	lefts, _ := x.left.eval(e, input)
+
+	var outputs []interface{}
+	for _, left := range lefts {
+		if isTruthy(left) {
+			outputs = append(outputs, left)
+		}
+	}
+	if len(outputs) > 0 {
+		return outputs, nil
+	}
+	return x.right.eval(e, input)
+}
+
+type arrayExpr struct {
+	body expr
+}
+
+func (x arrayExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	if x.body == nil {
+		return []interface{}{[]interface{}{}}, nil
+	}
+
+	items, err := x.body.eval(e, input)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []interface{}{}
+	}
+	return []interface{}{items}, nil
+}
+
+type objectEntry struct {
+	key   expr
+	value expr
+}
+
+type objectExpr struct {
+	entries []objectEntry
+}
+
+func (x objectExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	objects := []map[string]interface{}{{}}
+
+	for _, entry := range x.entries {
+		keys, err := entry.key.eval(e, input)
+		if err != nil {
+			return nil, err
+		}
+		values, err := entry.value.eval(e, input)
+		if err != nil {
+			return nil, err
+		}
+
+		var next []map[string]interface{}
+		for _, object := range objects {
+			for _, key := range keys {
+				keyString, isString := key.(string)
+				if isString == false {
+					return nil, fmt.Errorf("Object keys must be strings, not %s", describe(key))
+				}
+				for _, value := range values {
+					copied := make(map[string]interface{}, len(object)+1)
+					for k, v := range object {
+						copied[k] = v
+					}
+					copied[keyString] = value
+					next = append(next, copied)
+				}
+			}
+		}
+		objects = next
+	}
+
+	outputs := make([]interface{}, len(objects))
+	for idx, object := range objects {
+		outputs[idx] = object
+	}
+	return outputs, nil
+}
+
+type variableExpr struct {
+	name string
+}
+
+func (x variableExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	value, ok := e.lookup(x.name)
+	if ok == false {
+		return nil, fmt.Errorf("$%s is not defined", x.name)
+	}
+	return []interface{}{value}, nil
+}
+
+// bindExpr is "source as $name | body".
+type bindExpr struct {
+	source expr
+	name   string
+	body   expr
+}
+
+func (x bindExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	values, err := x.source.eval(e, input)
+	if err != nil {
+		return nil, err
+	}
+
+	var outputs []interface{}
+	for _, value := range values {
+		results, err := x.body.eval(e.bind(x.name, value), input)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, results...)
+	}
+	return outputs, nil
+}
+
+// reduceExpr is "reduce source as $name (init; update)".
+type reduceExpr struct {
+	source expr
+	name   string
+	init   expr
+	update expr
+}
+
+func (x reduceExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	inits, err := x.init.eval(e, input)
+	if err != nil {
+		return nil, err
+	}
+	values, err := x.source.eval(e, input)
+	if err != nil {
+		return nil, err
+	}
+
+	var outputs []interface{}
+	for _, accumulator := range inits {
+		for _, value := range values {
+			results, err := x.update.eval(e.bind(x.name, value), accumulator)
+			if err != nil {
+				return nil, err
+			}
+			if len(results) == 0 {
+				accumulator = nil
+				continue
+			}
+			accumulator = results[len(results)-1]
+		}
+		outputs = append(outputs, accumulator)
+	}
+	return outputs, nil
+}
+
+// ifExpr is "if cond then then (elif ...) else otherwise end", elif is represented by nesting.
+type ifExpr struct {
+	cond      expr
+	then      expr
+	otherwise expr
+}
+
+func (x ifExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	conds, err := x.cond.eval(e, input)
+	if err != nil {
+		return nil, err
+	}
+
+	var outputs []interface{}
+	for _, cond := range conds {
+		branch := x.otherwise
+		if isTruthy(cond) {
+			branch = x.then
+		}
+		results, err := branch.eval(e, input)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, results...)
+	}
+	return outputs, nil
+}
+
+// tryExpr is "try body catch handler" and the postfix "?", which has no handler.
+type tryExpr struct {
+	body    expr
+	handler expr
+}
+
+func (x tryExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	outputs, err := x.body.eval(e, input)
+	if err == nil {
+		return outputs, nil
+	}
+	if x.handler == nil {
+		return nil, nil
+	}
+
+	var message interface{} = err.Error()
+	if userErr, ok := err.(*userError); ok {
+		message = userErr.value
+	}
+	return x.handler.eval(e, message)
+}
+
+// userError is raised by the error builtin, the value is passed to catch.
+type userError struct {
+	value interface{}
+}
+
+func (err *userError) Error() string {
+	if str, ok := err.value.(string); ok {
+		return str
+	}
+	return toJSON(err.value) + " (not a string)"
+}
+
+type callExpr struct {
+	name string
+	args []expr
+}
+
+func (x callExpr) eval(e *env, input interface{}) ([]interface{}, error) {
+	fn, ok := builtins[builtinKey(x.name, len(x.args))]
+	if ok == false {
+		return nil, fmt.Errorf("%s/%d is not defined", x.name, len(x.args))
+	}
+	return fn(e, input, x.args)
+}
+
+func (x callExpr) evalLimited(e *env, input interface{}, n int) ([]interface{}, error) {
+	if x.name == "range" && (len(x.args) == 1 || len(x.args) == 2) {
+		return rangeOutputs(e, input, x.args, n)
+	}
+	outputs, err := x.eval(e, input)
+	return truncate(outputs, n), err
+}
+
+func builtinKey(name string, arity int) string {
+	return fmt.Sprintf("%s/%d", name, arity)
+}
+
+// describe is used for error messages, like jq does.
+func describe(value interface{}) string {
+	str := toJSON(value)
+	if len(str) > 11 {
+		str = str[:10] + "..."
+	}
+	return fmt.Sprintf("%s (%s)", typeName(value), strings.TrimSpace(str))
+}
@@ -0,0 +1,1003 @@
+package jq
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// builtin is the implementation of a builtin function. Arguments are passed unevaluated,
+// because most functions take filters that are applied to their input, like map(f).
+type builtin func(e *env, input interface{}, args []expr) ([]interface{}, error)
+
+// builtins are registered by "name/arity", like jq does.
+var builtins map[string]builtin
+
+func init() {
+	builtins = map[string]builtin{
+		"empty/0": func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+			return nil, nil
+		},
+		"error/0": func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+			return nil, &userError{input}
+		},
+		"error/1": valueFn(func(input interface{}, values []interface{}) (interface{}, error) {
+			return nil, &userError{values[0]}
+		}),
+		"not/0": simpleFn(func(input interface{}) (interface{}, error) {
+			return isTruthy(input) == false, nil
+		}),
+		"length/0":         simpleFn(length),
+		"utf8bytelength/0": simpleFn(utf8ByteLength),
+		"type/0": simpleFn(func(input interface{}) (interface{}, error) {
+			return typeName(input), nil
+		}),
+		"keys/0":          simpleFn(keys),
+		"keys_unsorted/0": simpleFn(keys),
+		"has/1": valueFn(func(input interface{}, values []interface{}) (interface{}, error) {
+			return has(input, values[0])
+		}),
+		"in/1": valueFn(func(input interface{}, values []interface{}) (interface{}, error) {
+			return has(values[0], input)
+		}),
+		"contains/1": valueFn(func(input interface{}, values []interface{}) (interface{}, error) {
+			return contains(input, values[0])
+		}),
+		"inside/1": valueFn(func(input interface{}, values []interface{}) (interface{}, error) {
+			return contains(values[0], input)
+		}),
+		"select/1":     selectFn,
+		"map/1":        mapFn,
+		"map_values/1": mapValuesFn,
+		"recurse/0": func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+			return appendRecursive(nil, input), nil
+		},
+		"recurse/1": func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+			return recurse(e, input, args[0], 0)
+		},
+		"walk/1": func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+			return walk(e, input, args[0])
+		},
+		"add/0": simpleFn(func(input interface{}) (interface{}, error) {
+			items, err := iterate(input)
+			if err != nil {
+				return nil, err
+			}
+			var sum interface{}
+			for _, item := range items {
+				if sum, err = add(sum, item); err != nil {
+					return nil, err
+				}
+			}
+			return sum, nil
+		}),
+		"any/0": simpleFn(func(input interface{}) (interface{}, error) {
+			return anyAll(input, true)
+		}),
+		"all/0": simpleFn(func(input interface{}) (interface{}, error) {
+			return anyAll(input, false)
+		}),
+		"any/1": func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+			return anyAllBy(e, input, args[0], true)
+		},
+		"all/1": func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+			return anyAllBy(e, input, args[0], false)
+		},
+		"range/1": valueFn(nil),
+		"range/2": valueFn(nil),
+		"floor/0": mathFn(math.Floor),
+		"ceil/0":  mathFn(math.Ceil),
+		"round/0": mathFn(math.Round),
+		"sqrt/0":  mathFn(math.Sqrt),
+		"fabs/0":  mathFn(math.Abs),
+		"abs/0":   mathFn(math.Abs),
+		"tostring/0": simpleFn(func(input interface{}) (interface{}, error) {
+			if str, ok := input.(string); ok {
+				return str, nil
+			}
+			return toJSON(input), nil
+		}),
+		"tonumber/0": simpleFn(func(input interface{}) (interface{}, error) {
+			switch v := input.(type) {
+			case float64:
+				return v, nil
+			case string:
+				number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+				if err != nil {
+					return nil, fmt.Errorf("Cannot parse '%s' as number", v)
+				}
+				return number, nil
+			}
+			return nil, fmt.Errorf("%s cannot be parsed as a number", describe(input))
+		}),
+		"tojson/0": simpleFn(func(input interface{}) (interface{}, error) {
+			return toJSON(input), nil
+		}),
+		"fromjson/0": simpleFn(func(input interface{}) (interface{}, error) {
+			str, ok := input.(string)
+			if ok == false {
+				return nil, fmt.Errorf("%s cannot be parsed as JSON", describe(input))
+			}
+			var value interface{}
+			if err := json.Unmarshal([]byte(str), &value); err != nil {
+				return nil, fmt.Errorf("%s (while parsing '%s')", err.Error(), str)
+			}
+			return value, nil
+		}),
+		"ascii_downcase/0": stringFn(strings.ToLower),
+		"ascii_upcase/0":   stringFn(strings.ToUpper),
+		"ltrimstr/1": valueFn(func(input interface{}, values []interface{}) (interface{}, error) {
+			str, strOk := input.(string)
+			prefix, prefixOk := values[0].(string)
+			if strOk && prefixOk {
+				return strings.TrimPrefix(str, prefix), nil
+			}
+			return input, nil
+		}),
+		"rtrimstr/1": valueFn(func(input interface{}, values []interface{}) (interface{}, error) {
+			str, strOk := input.(string)
+			suffix, suffixOk := values[0].(string)
+			if strOk && suffixOk {
+				return strings.TrimSuffix(str, suffix), nil
+			}
+			return input, nil
+		}),
+		"startswith/1": stringPairFn("startswith", strings.HasPrefix),
+		"endswith/1":   stringPairFn("endswith", strings.HasSuffix),
+		"split/1": valueFn(func(input interface{}, values []interface{}) (interface{}, error) {
+			str, strOk := input.(string)
+			separator, separatorOk := values[0].(string)
+			if strOk == false || separatorOk == false {
+				return nil, fmt.Errorf("split input and separator must be strings")
+			}
+			return splitString(str, separator), nil
+		}),
+		"join/1": valueFn(join),
+		"test/1": valueFn(func(input interface{}, values []interface{}) (interface{}, error) {
+			return test(input, values[0], nil)
+		}),
+		"test/2": valueFn(func(input interface{}, values []interface{}) (interface{}, error) {
+			return test(input, values[0], values[1])
+		}),
+		"sort/0": simpleFn(func(input interface{}) (interface{}, error) {
+			items, ok := input.([]interface{})
+			if ok == false {
+				return nil, fmt.Errorf("%s cannot be sorted, as it is not an array", describe(input))
+			}
+			sorted := append([]interface{}{}, items...)
+			sort.SliceStable(sorted, func(i, j int) bool {
+				return compare(sorted[i], sorted[j]) < 0
+			})
+			return sorted, nil
+		}),
+		"sort_by/1": func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+			return byKey(e, input, args[0], sortByKeys)
+		},
+		"group_by/1": func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+			return byKey(e, input, args[0], groupByKeys)
+		},
+		"unique/0": simpleFn(func(input interface{}) (interface{}, error) {
+			items, ok := input.([]interface{})
+			if ok == false {
+				return nil, fmt.Errorf("%s cannot be sorted, as it is not an array", describe(input))
+			}
+			return uniqueByKeys(items, items), nil
+		}),
+		"unique_by/1": func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+			return byKey(e, input, args[0], uniqueByKeys)
+		},
+		"min/0": simpleFn(func(input interface{}) (interface{}, error) {
+			return extreme(input, input, -1)
+		}),
+		"max/0": simpleFn(func(input interface{}) (interface{}, error) {
+			return extreme(input, input, 1)
+		}),
+		"min_by/1": func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+			return byKey(e, input, args[0], func(items []interface{}, keys []interface{}) interface{} {
+				value, _ := extreme(items, keys, -1)
+				return value
+			})
+		},
+		"max_by/1": func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+			return byKey(e, input, args[0], func(items []interface{}, keys []interface{}) interface{} {
+				value, _ := extreme(items, keys, 1)
+				return value
+			})
+		},
+		"reverse/0": simpleFn(func(input interface{}) (interface{}, error) {
+			switch v := input.(type) {
+			case nil:
+				return []interface{}{}, nil
+			case string:
+				runes := []rune(v)
+				for left, right := 0, len(runes)-1; left < right; left, right = left+1, right-1 {
+					runes[left], runes[right] = runes[right], runes[left]
+				}
+				return string(runes), nil
+			case []interface{}:
+				reversed := make([]interface{}, len(v))
+				for idx, item := range v {
+					reversed[len(v)-1-idx] = item
+				}
+				return reversed, nil
+			}
+			return nil, fmt.Errorf("Cannot reverse %s", describe(input))
+		}),
+		"flatten/0": simpleFn(func(input interface{}) (interface{}, error) {
+			return flatten(input, -1)
+		}),
+		"flatten/1": valueFn(func(input interface{}, values []interface{}) (interface{}, error) {
+			depth, ok := values[0].(float64)
+			if ok == false || depth < 0 {
+				return nil, fmt.Errorf("flatten depth must not be negative")
+			}
+			return flatten(input, int(depth))
+		}),
+		"to_entries/0":   simpleFn(toEntries),
+		"from_entries/0": simpleFn(fromEntries),
+		"with_entries/1": func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+			entries, err := toEntries(input)
+			if err != nil {
+				return nil, err
+			}
+			mapped, err := mapFn(e, entries, args)
+			if err != nil {
+				return nil, err
+			}
+			object, err := fromEntries(mapped[0])
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{object}, nil
+		},
+		"first/0": simpleFn(func(input interface{}) (interface{}, error) {
+			return indexValue(input, float64(0))
+		}),
+		"last/0": simpleFn(func(input interface{}) (interface{}, error) {
+			return indexValue(input, float64(-1))
+		}),
+		"nth/1": valueFn(func(input interface{}, values []interface{}) (interface{}, error) {
+			return indexValue(input, values[0])
+		}),
+		"first/1": func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+			return evalLimited(args[0], e, input, 1)
+		},
+		"last/1": func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+			outputs, err := args[0].eval(e, input)
+			if err != nil || len(outputs) == 0 {
+				return nil, err
+			}
+			return outputs[len(outputs)-1:], nil
+		},
+		"limit/2": func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+			limits, err := args[0].eval(e, input)
+			if err != nil {
+				return nil, err
+			}
+			var limited []interface{}
+			for _, limit := range limits {
+				n, ok := limit.(float64)
+				if ok == false {
+					return nil, fmt.Errorf("Invalid limit %s", describe(limit))
+				}
+				// Clamped before the conversion, huge limits would overflow
+				outputs, err := evalLimited(args[1], e, input, int(math.Max(math.Min(n, math.MaxInt32), 0)))
+				if err != nil {
+					return nil, err
+				}
+				limited = append(limited, outputs...)
+			}
+			return limited, nil
+		},
+		"paths/0": simpleFn(func(input interface{}) (interface{}, error) {
+			return nil, nil
+		}),
+		"getpath/1": valueFn(func(input interface{}, values []interface{}) (interface{}, error) {
+			path, ok := values[0].([]interface{})
+			if ok == false {
+				return nil, fmt.Errorf("Path must be specified as an array")
+			}
+			current := input
+			for _, segment := range path {
+				if current == nil {
+					return nil, nil
+				}
+				value, err := indexValue(current, segment)
+				if err != nil {
+					return nil, err
+				}
+				current = value
+			}
+			return current, nil
+		}),
+		"splits/1": func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+			outputs, err := valueFn(func(input interface{}, values []interface{}) (interface{}, error) {
+				str, strOk := input.(string)
+				separator, separatorOk := values[0].(string)
+				if strOk == false || separatorOk == false {
+					return nil, fmt.Errorf("splits input and separator must be strings")
+				}
+				return splitString(str, separator), nil
+			})(e, input, args)
+			if err != nil {
+				return nil, err
+			}
+			var parts []interface{}
+			for _, output := range outputs {
+				parts = append(parts, output.([]interface{})...)
+			}
+			return parts, nil
+		},
+	}
+
+	builtins["range/1"] = rangeFn
+	builtins["range/2"] = rangeFn
+	builtins["paths/0"] = func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+		return appendPaths(nil, nil, input, nil), nil
+	}
+	builtins["paths/1"] = func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+		return appendPaths(nil, nil, input, func(value interface{}) (bool, error) {
+			outputs, err := args[0].eval(e, value)
+			if err != nil {
+				return false, err
+			}
+			return len(outputs) > 0 && isTruthy(outputs[0]), nil
+		}), nil
+	}
+
+	// Type filters like "objects" are just selects
+	for name, typ := range map[string]string{
+		"nulls":    "null",
+		"booleans": "boolean",
+		"numbers":  "number",
+		"strings":  "string",
+		"arrays":   "array",
+		"objects":  "object",
+	} {
+		typ := typ
+		builtins[name+"/0"] = func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+			if typeName(input) == typ {
+				return []interface{}{input}, nil
+			}
+			return nil, nil
+		}
+	}
+	builtins["iterables/0"] = func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+		switch input.(type) {
+		case []interface{}, map[string]interface{}:
+			return []interface{}{input}, nil
+		}
+		return nil, nil
+	}
+	builtins["scalars/0"] = func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+		switch input.(type) {
+		case []interface{}, map[string]interface{}:
+			return nil, nil
+		}
+		return []interface{}{input}, nil
+	}
+	builtins["values/0"] = func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+		if input == nil {
+			return nil, nil
+		}
+		return []interface{}{input}, nil
+	}
+}
+
+// simpleFn wraps functions without arguments and exactly one output.
+func simpleFn(fn func(input interface{}) (interface{}, error)) builtin {
+	return func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+		output, err := fn(input)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{output}, nil
+	}
+}
+
+// valueFn wraps functions whose arguments are evaluated as values, with one output
+// for every combination of argument values.
+func valueFn(fn func(input interface{}, values []interface{}) (interface{}, error)) builtin {
+	return func(e *env, input interface{}, args []expr) ([]interface{}, error) {
+		combinations := [][]interface{}{{}}
+		for _, arg := range args {
+			values, err := arg.eval(e, input)
+			if err != nil {
+				return nil, err
+			}
+			var next [][]interface{}
+			for _, combination := range combinations {
+				for _, value := range values {
+					next = append(next, append(append([]interface{}{}, combination...), value))
+				}
+			}
+			combinations = next
+		}
+
+		var outputs []interface{}
+		for _, combination := range combinations {
+			output, err := fn(input, combination)
+			if err != nil {
+				return nil, err
+			}
+			outputs = append(outputs, output)
+		}
+		return outputs, nil
+	}
+}
+
+func mathFn(fn func(float64) float64) builtin {
+	return simpleFn(func(input interface{}) (interface{}, error) {
+		number, ok := input.(float64)
+		if ok == false {
+			return nil, fmt.Errorf("%s number required", describe(input))
+		}
+		return fn(number), nil
+	})
+}
+
+func stringFn(fn func(string) string) builtin {
+	return simpleFn(func(input interface{}) (interface{}, error) {
+		str, ok := input.(string)
+		if ok == false {
+			return nil, fmt.Errorf("%s cannot be case-converted, as it is not a string", describe(input))
+		}
+		return fn(str), nil
+	})
+}
+
+func stringPairFn(name string, fn func(string, string) bool) builtin {
+	return valueFn(func(input interface{}, values []interface{}) (interface{}, error) {
+		str, strOk := input.(string)
+		other, otherOk := values[0].(string)
+		if strOk == false || otherOk == false {
+			return nil, fmt.Errorf("%s() requires string inputs", name)
+		}
+		return fn(str, other), nil
+	})
+}
+
+func rangeFn(e *env, input interface{}, args []expr) ([]interface{}, error) {
+	return rangeOutputs(e, input, args, math.MaxInt32)
+}
+
+// rangeOutputs generates at most n numbers of the ranges.
+func rangeOutputs(e *env, input interface{}, args []expr, n int) ([]interface{}, error) {
+	bounds, err := valueFn(func(input interface{}, values []interface{}) (interface{}, error) {
+		return values, nil
+	})(e, input, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var outputs []interface{}
+	for _, bound := range bounds {
+		values := bound.([]interface{})
+		from, to := 0.0, 0.0
+		var fromOk, toOk bool
+		if len(values) == 1 {
+			to, toOk = values[0].(float64)
+			fromOk = true
+		} else {
+			from, fromOk = values[0].(float64)
+			to, toOk = values[1].(float64)
+		}
+		if fromOk == false || toOk == false {
+			return nil, fmt.Errorf("Range bounds must be numeric")
+		}
+		for value := from; value < to && len(outputs) < n; value++ {
+			outputs = append(outputs, value)
+		}
+	}
+	return outputs, nil
+}
+
+func length(input interface{}) (interface{}, error) {
+	switch v := input.(type) {
+	case nil:
+		return float64(0), nil
+	case float64:
+		return math.Abs(v), nil
+	case string:
+		return float64(utf8.RuneCountInString(v)), nil
+	case []interface{}:
+		return float64(len(v)), nil
+	case map[string]interface{}:
+		return float64(len(v)), nil
+	}
+	return nil, fmt.Errorf("%s has no length", describe(input))
+}
+
+func utf8ByteLength(input interface{}) (interface{}, error) {
+	str, ok := input.(string)
+	if ok == false {
+		return nil, fmt.Errorf("%s only strings have UTF-8 byte length", describe(input))
+	}
+	return float64(len(str)), nil
+}
+
+func keys(input interface{}) (interface{}, error) {
+	switch v := input.(type) {
+	case map[string]interface{}:
+		return toInterfaces(sortedKeys(v)), nil
+	case []interface{}:
+		indices := make([]interface{}, len(v))
+		for idx := range v {
+			indices[idx] = float64(idx)
+		}
+		return indices, nil
+	}
+	return nil, fmt.Errorf("%s has no keys", describe(input))
+}
+
+func has(input interface{}, key interface{}) (interface{}, error) {
+	switch v := input.(type) {
+	case map[string]interface{}:
+		if k, ok := key.(string); ok {
+			_, exists := v[k]
+			return exists, nil
+		}
+	case []interface{}:
+		if idx, ok := key.(float64); ok {
+			return idx >= 0 && int(idx) < len(v), nil
+		}
+	}
+	return nil, fmt.Errorf("Cannot check whether %s has a %s key", typeName(input), typeName(key))
+}
+
+func contains(haystack interface{}, needle interface{}) (interface{}, error) {
+	if typeName(haystack) != typeName(needle) {
+		return nil, fmt.Errorf("%s and %s cannot have their containment checked", describe(haystack), describe(needle))
+	}
+
+	switch h := haystack.(type) {
+	case string:
+		return strings.Contains(h, needle.(string)), nil
+
+	case []interface{}:
+		for _, n := range needle.([]interface{}) {
+			var found bool
+			for _, item := range h {
+				if typeName(item) != typeName(n) {
+					continue
+				}
+				if result, err := contains(item, n); err == nil && result == true {
+					found = true
+					break
+				}
+			}
+			if found == false {
+				return false, nil
+			}
+		}
+		return true, nil
+
+	case map[string]interface{}:
+		for key, n := range needle.(map[string]interface{}) {
+			value, exists := h[key]
+			if exists == false || typeName(value) != typeName(n) {
+				return false, nil
+			}
+			if result, err := contains(value, n); err != nil || result == false {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	return equals(haystack, needle), nil
+}
+
+func selectFn(e *env, input interface{}, args []expr) ([]interface{}, error) {
+	conds, err := args[0].eval(e, input)
+	if err != nil {
+		return nil, err
+	}
+	var outputs []interface{}
+	for _, cond := range conds {
+		if isTruthy(cond) {
+			outputs = append(outputs, input)
+		}
+	}
+	return outputs, nil
+}
+
+func mapFn(e *env, input interface{}, args []expr) ([]interface{}, error) {
+	items, err := iterate(input)
+	if err != nil {
+		return nil, err
+	}
+	mapped := []interface{}{}
+	for _, item := range items {
+		outputs, err := args[0].eval(e, item)
+		if err != nil {
+			return nil, err
+		}
+		mapped = append(mapped, outputs...)
+	}
+	return []interface{}{mapped}, nil
+}
+
+func mapValuesFn(e *env, input interface{}, args []expr) ([]interface{}, error) {
+	switch v := input.(type) {
+	case map[string]interface{}:
+		mapped := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			outputs, err := args[0].eval(e, value)
+			if err != nil {
+				return nil, err
+			}
+			if len(outputs) > 0 {
+				mapped[key] = outputs[0]
+			}
+		}
+		return []interface{}{mapped}, nil
+
+	case []interface{}:
+		mapped := []interface{}{}
+		for _, value := range v {
+			outputs, err := args[0].eval(e, value)
+			if err != nil {
+				return nil, err
+			}
+			if len(outputs) > 0 {
+				mapped = append(mapped, outputs[0])
+			}
+		}
+		return []interface{}{mapped}, nil
+	}
+	return nil, fmt.Errorf("Cannot iterate over %s", describe(input))
+}
+
+// maxRecursion prevents endless recursion with filters like recurse(.)
+const maxRecursion = 1000
+
+func recurse(e *env, input interface{}, fn expr, depth int) ([]interface{}, error) {
+	if depth > maxRecursion {
+		return nil, fmt.Errorf("Maximum recursion depth exceeded")
+	}
+	outputs := []interface{}{input}
+	children, err := fn.eval(e, input)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		descendants, err := recurse(e, child, fn, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, descendants...)
+	}
+	return outputs, nil
+}
+
+func walk(e *env, input interface{}, fn expr) ([]interface{}, error) {
+	var walked interface{}
+	switch v := input.(type) {
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			outputs, err := walk(e, value, fn)
+			if err != nil {
+				return nil, err
+			}
+			if len(outputs) > 0 {
+				object[key] = outputs[len(outputs)-1]
+			}
+		}
+		walked = object
+
+	case []interface{}:
+		items := []interface{}{}
+		for _, value := range v {
+			outputs, err := walk(e, value, fn)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, outputs...)
+		}
+		walked = items
+
+	default:
+		walked = input
+	}
+	return fn.eval(e, walked)
+}
+
+func iterate(input interface{}) ([]interface{}, error) {
+	switch v := input.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		return v, nil
+	case map[string]interface{}:
+		items := make([]interface{}, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			items = append(items, v[key])
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("Cannot iterate over %s", describe(input))
+}
+
+func anyAll(input interface{}, any bool) (interface{}, error) {
+	items, err := iterate(input)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if isTruthy(item) == any {
+			return any, nil
+		}
+	}
+	return any == false, nil
+}
+
+func anyAllBy(e *env, input interface{}, fn expr, any bool) ([]interface{}, error) {
+	items, err := iterate(input)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		outputs, err := fn.eval(e, item)
+		if err != nil {
+			return nil, err
+		}
+		for _, output := range outputs {
+			if isTruthy(output) == any {
+				return []interface{}{any}, nil
+			}
+		}
+	}
+	return []interface{}{any == false}, nil
+}
+
+func join(input interface{}, values []interface{}) (interface{}, error) {
+	separator, ok := values[0].(string)
+	if ok == false {
+		return nil, fmt.Errorf("join separator must be a string")
+	}
+	items, err := iterate(input)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]string, len(items))
+	for idx, item := range items {
+		switch v := item.(type) {
+		case nil:
+			parts[idx] = ""
+		case string:
+			parts[idx] = v
+		case float64, bool:
+			parts[idx] = toJSON(v)
+		default:
+			return nil, fmt.Errorf("Cannot join with %s", describe(item))
+		}
+	}
+	return strings.Join(parts, separator), nil
+}
+
+func test(input interface{}, pattern interface{}, flags interface{}) (interface{}, error) {
+	str, strOk := input.(string)
+	regex, regexOk := pattern.(string)
+	if strOk == false || regexOk == false {
+		return nil, fmt.Errorf("%s cannot be matched, as it is not a string", describe(input))
+	}
+	if flagStr, ok := flags.(string); ok {
+		if strings.Contains(flagStr, "i") {
+			regex = "(?i)" + regex
+		}
+		if strings.Contains(flagStr, "x") {
+			regex = regexp.MustCompile(`\s+`).ReplaceAllString(regex, "")
+		}
+	}
+	compiled, err := regexp.Compile(regex)
+	if err != nil {
+		return nil, fmt.Errorf("%s (at offset 0) is not a valid regex: %s", regex, err.Error())
+	}
+	return compiled.MatchString(str), nil
+}
+
+// byKey evaluates the filter for every item as its key, "[f]" like jq does, and passes
+// both to the actual function.
+func byKey(e *env, input interface{}, fn expr, by func(items []interface{}, keys []interface{}) interface{}) ([]interface{}, error) {
+	items, ok := input.([]interface{})
+	if ok == false {
+		return nil, fmt.Errorf("Cannot index %s with number", typeName(input))
+	}
+
+	keys := make([]interface{}, len(items))
+	for idx, item := range items {
+		outputs, err := fn.eval(e, item)
+		if err != nil {
+			return nil, err
+		}
+		if outputs == nil {
+			outputs = []interface{}{}
+		}
+		keys[idx] = outputs
+	}
+	return []interface{}{by(items, keys)}, nil
+}
+
+type keyedItem struct {
+	key  interface{}
+	item interface{}
+}
+
+func sortedByKeys(items []interface{}, keys []interface{}) []keyedItem {
+	keyed := make([]keyedItem, len(items))
+	for idx, item := range items {
+		keyed[idx] = keyedItem{keys[idx], item}
+	}
+	sort.SliceStable(keyed, func(i, j int) bool {
+		return compare(keyed[i].key, keyed[j].key) < 0
+	})
+	return keyed
+}
+
+func sortByKeys(items []interface{}, keys []interface{}) interface{} {
+	sorted := make([]interface{}, len(items))
+	for idx, keyed := range sortedByKeys(items, keys) {
+		sorted[idx] = keyed.item
+	}
+	return sorted
+}
+
+func groupByKeys(items []interface{}, keys []interface{}) interface{} {
+	groups := []interface{}{}
+	var lastKey interface{}
+	for idx, keyed := range sortedByKeys(items, keys) {
+		if idx == 0 || compare(lastKey, keyed.key) != 0 {
+			groups = append(groups, []interface{}{})
+		}
+		last := len(groups) - 1
+		groups[last] = append(groups[last].([]interface{}), keyed.item)
+		lastKey = keyed.key
+	}
+	return groups
+}
+
+func uniqueByKeys(items []interface{}, keys []interface{}) interface{} {
+	unique := []interface{}{}
+	var lastKey interface{}
+	for idx, keyed := range sortedByKeys(items, keys) {
+		if idx == 0 || compare(lastKey, keyed.key) != 0 {
+			unique = append(unique, keyed.item)
+		}
+		lastKey = keyed.key
+	}
+	return unique
+}
+
+// extreme finds the item with the minimal (direction -1) or maximal (direction 1) key.
+func extreme(input interface{}, keysInput interface{}, direction int) (interface{}, error) {
+	items, ok := input.([]interface{})
+	if ok == false {
+		return nil, fmt.Errorf("Cannot index %s with number", typeName(input))
+	}
+	keys := keysInput.([]interface{})
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	best := 0
+	for idx := 1; idx < len(items); idx++ {
+		c := compare(keys[idx], keys[best])
+		// jq returns the last maximum, but the first minimum
+		if c == direction || (c == 0 && direction > 0) {
+			best = idx
+		}
+	}
+	return items[best], nil
+}
+
+func flatten(input interface{}, depth int) (interface{}, error) {
+	items, ok := input.([]interface{})
+	if ok == false {
+		return nil, fmt.Errorf("Cannot flatten %s", describe(input))
+	}
+
+	flat := []interface{}{}
+	for _, item := range items {
+		nested, isArray := item.([]interface{})
+		if isArray == false || depth == 0 {
+			flat = append(flat, item)
+			continue
+		}
+		flattened, err := flatten(nested, depth-1)
+		if err != nil {
+			return nil, err
+		}
+		flat = append(flat, flattened.([]interface{})...)
+	}
+	return flat, nil
+}
+
+func toEntries(input interface{}) (interface{}, error) {
+	object, ok := input.(map[string]interface{})
+	if ok == false {
+		return nil, fmt.Errorf("%s has no keys", describe(input))
+	}
+	entries := make([]interface{}, 0, len(object))
+	for _, key := range sortedKeys(object) {
+		entries = append(entries, map[string]interface{}{
+			"key":   key,
+			"value": object[key],
+		})
+	}
+	return entries, nil
+}
+
+func fromEntries(input interface{}) (interface{}, error) {
+	items, err := iterate(input)
+	if err != nil {
+		return nil, err
+	}
+
+	object := make(map[string]interface{}, len(items))
+	for _, item := range items {
+		entry, ok := item.(map[string]interface{})
+		if ok == false {
+			return nil, fmt.Errorf("Cannot index %s with \"key\"", typeName(item))
+		}
+
+		var key interface{}
+		for _, name := range []string{"key", "k", "name", "Name", "K", "Key"} {
+			if value, exists := entry[name]; exists && value != nil && value != false {
+				key = value
+				break
+			}
+		}
+		var value interface{}
+		for _, name := range []string{"value", "v", "Value", "V"} {
+			if v, exists := entry[name]; exists {
+				value = v
+				break
+			}
+		}
+
+		switch k := key.(type) {
+		case string:
+			object[k] = value
+		case float64, bool:
+			object[toJSON(k)] = value
+		default:
+			return nil, fmt.Errorf("Cannot use %s as object key", describe(key))
+		}
+	}
+	return object, nil
+}
+
+// appendPaths appends the paths of all descendants, optionally filtered by their values.
+func appendPaths(paths []interface{}, prefix []interface{}, value interface{}, filter func(interface{}) (bool, error)) []interface{} {
+	visit := func(segment interface{}, child interface{}) {
+		path := append(append([]interface{}{}, prefix...), segment)
+		matches := true
+		if filter != nil {
+			matches, _ = filter(child)
+		}
+		if matches {
+			paths = append(paths, path)
+		}
+		paths = appendPaths(paths, path, child, filter)
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		for idx, child := range v {
+			visit(float64(idx), child)
+		}
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			visit(key, v[key])
+		}
+	}
+	return paths
+}
@@ -0,0 +1,51 @@
+// Package jq implements a subset of the jq language (https://stedolan.github.io/jq/manual/)
+// for filtering and transforming JSON-compatible values.
+//
+// Supported are paths, iteration, slices, recursion, pipes, commas, alternatives, arithmetic,
+// comparisons, boolean operators, array/object construction, string interpolation,
+// conditionals, variables, reduce, try/catch and the most common builtin functions.
+// Assignments and user-defined functions aren't supported.
+package jq
+
+// Query is a parsed jq expression, it can be evaluated multiple times.
+type Query struct {
+	expression string
+	root       expr
+}
+
+// Parse parses a jq expression.
+func Parse(expression string) (*Query, error) {
+	p, err := newParser(expression)
+	if err != nil {
+		return nil, err
+	}
+	root, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	return &Query{
+		expression: expression,
+		root:       root,
+	}, nil
+}
+
+// String returns the original expression.
+func (q *Query) String() string {
+	return q.expression
+}
+
+// Evaluate runs the query against the input and returns all outputs.
+// The input must only consist of JSON-compatible types (map[string]interface{},
+// []interface{}, string, float64, bool and nil).
+func (q *Query) Evaluate(input interface{}) ([]interface{}, error) {
+	return q.root.eval(nil, input)
+}
+
+// Evaluate is a convenience function to parse and evaluate an expression in one step.
+func Evaluate(expression string, input interface{}) ([]interface{}, error) {
+	q, err := Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+	return q.Evaluate(input)
+}
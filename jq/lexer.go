@@ -0,0 +1,285 @@
+package jq
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenDot
+	tokenRecurse
+	tokenField
+	tokenIdent
+	tokenVariable
+	tokenNumber
+	tokenString
+	tokenOperator
+)
+
+// stringPart is either a literal part of a string or an interpolated expression "\(...)".
+type stringPart struct {
+	literal string
+	expr    expr
+}
+
+type token struct {
+	kind   tokenKind
+	value  string
+	number float64
+	parts  []stringPart
+	pos    int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of input"
+	case tokenString:
+		return "string"
+	default:
+		return "'" + t.value + "'"
+	}
+}
+
+// operators are sorted by length, so the longest match wins.
+var operators = []string{
+	"?//", "|=", "+=", "-=", "*=", "/=", "%=", "//=",
+	"==", "!=", "<=", ">=", "//",
+	"|", ",", ":", ";", "(", ")", "[", "]", "{", "}", "?",
+	"<", ">", "+", "-", "*", "/", "%", "=",
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	pos := 0
+
+	for pos < len(input) {
+		c := input[pos]
+
+		switch {
+		case unicode.IsSpace(rune(c)):
+			pos++
+
+		case c == '#':
+			for pos < len(input) && input[pos] != '\n' {
+				pos++
+			}
+
+		case c == '.':
+			start := pos
+			pos++
+			switch {
+			case pos < len(input) && input[pos] == '.':
+				pos++
+				tokens = append(tokens, token{kind: tokenRecurse, value: "..", pos: start})
+			case pos < len(input) && isIdentStart(input[pos]):
+				end := scanIdent(input, pos)
+				tokens = append(tokens, token{kind: tokenField, value: input[pos:end], pos: start})
+				pos = end
+			case pos < len(input) && input[pos] == '"':
+				value, end, err := scanString(input, pos)
+				if err != nil {
+					return nil, err
+				}
+				if len(value) != 1 || value[0].expr != nil {
+					return nil, fmt.Errorf("Interpolated strings can't be used as field names (position %d)", start+1)
+				}
+				tokens = append(tokens, token{kind: tokenField, value: value[0].literal, pos: start})
+				pos = end
+			default:
+				tokens = append(tokens, token{kind: tokenDot, value: ".", pos: start})
+			}
+
+		case c == '$':
+			start := pos
+			pos++
+			if pos >= len(input) || isIdentStart(input[pos]) == false {
+				return nil, fmt.Errorf("Invalid variable name at position %d", start+1)
+			}
+			end := scanIdent(input, pos)
+			tokens = append(tokens, token{kind: tokenVariable, value: input[pos:end], pos: start})
+			pos = end
+
+		case isIdentStart(c):
+			end := scanIdent(input, pos)
+			tokens = append(tokens, token{kind: tokenIdent, value: input[pos:end], pos: pos})
+			pos = end
+
+		case c >= '0' && c <= '9':
+			end := scanNumber(input, pos)
+			number, err := strconv.ParseFloat(input[pos:end], 64)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid number '%s' at position %d", input[pos:end], pos+1)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: input[pos:end], number: number, pos: pos})
+			pos = end
+
+		case c == '"':
+			parts, end, err := scanString(input, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, parts: parts, pos: pos})
+			pos = end
+
+		default:
+			var matched bool
+			for _, op := range operators {
+				if strings.HasPrefix(input[pos:], op) {
+					tokens = append(tokens, token{kind: tokenOperator, value: op, pos: pos})
+					pos += len(op)
+					matched = true
+					break
+				}
+			}
+			if matched == false {
+				return nil, fmt.Errorf("Unexpected character '%c' at position %d", c, pos+1)
+			}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func scanIdent(input string, pos int) int {
+	for pos < len(input) {
+		c := input[pos]
+		if isIdentStart(c) == false && (c < '0' || c > '9') {
+			// jq allows "::" in identifiers for modules, we don't
+			break
+		}
+		pos++
+	}
+	return pos
+}
+
+func scanNumber(input string, pos int) int {
+	for pos < len(input) && input[pos] >= '0' && input[pos] <= '9' {
+		pos++
+	}
+	if pos < len(input) && input[pos] == '.' {
+		pos++
+		for pos < len(input) && input[pos] >= '0' && input[pos] <= '9' {
+			pos++
+		}
+	}
+	if pos < len(input) && (input[pos] == 'e' || input[pos] == 'E') {
+		pos++
+		if pos < len(input) && (input[pos] == '+' || input[pos] == '-') {
+			pos++
+		}
+		for pos < len(input) && input[pos] >= '0' && input[pos] <= '9' {
+			pos++
+		}
+	}
+	return pos
+}
+
+// scanString reads a double-quoted string starting at pos, including interpolations.
+func scanString(input string, pos int) ([]stringPart, int, error) {
+	var parts []stringPart
+	var literal strings.Builder
+	start := pos
+	pos++
+
+	for pos < len(input) {
+		c := input[pos]
+		switch c {
+		case '"':
+			if literal.Len() > 0 || len(parts) == 0 {
+				parts = append(parts, stringPart{literal: literal.String()})
+			}
+			return parts, pos + 1, nil
+
+		case '\\':
+			pos++
+			if pos >= len(input) {
+				break
+			}
+			switch escaped := input[pos]; escaped {
+			case 'n':
+				literal.WriteByte('\n')
+			case 't':
+				literal.WriteByte('\t')
+			case 'r':
+				literal.WriteByte('\r')
+			case 'b':
+				literal.WriteByte('\b')
+			case 'f':
+				literal.WriteByte('\f')
+			case 'u':
+				if pos+4 >= len(input) {
+					return nil, 0, fmt.Errorf("Invalid unicode escape at position %d", pos)
+				}
+				code, err := strconv.ParseUint(input[pos+1:pos+5], 16, 32)
+				if err != nil {
+					return nil, 0, fmt.Errorf("Invalid unicode escape at position %d", pos)
+				}
+				literal.WriteRune(rune(code))
+				pos += 4
+			case '(':
+				end, err := findInterpolationEnd(input, pos+1)
+				if err != nil {
+					return nil, 0, err
+				}
+				p, err := newParser(input[pos+1 : end])
+				if err != nil {
+					return nil, 0, err
+				}
+				interpolated, err := p.parse()
+				if err != nil {
+					return nil, 0, err
+				}
+				if literal.Len() > 0 {
+					parts = append(parts, stringPart{literal: literal.String()})
+					literal.Reset()
+				}
+				parts = append(parts, stringPart{expr: interpolated})
+				pos = end
+			default:
+				literal.WriteByte(escaped)
+			}
+			pos++
+
+		default:
+			literal.WriteByte(c)
+			pos++
+		}
+	}
+
+	return nil, 0, fmt.Errorf("Unterminated string starting at position %d", start+1)
+}
+
+// findInterpolationEnd finds the closing parenthesis of "\(", skipping nested parenthesis and strings.
+func findInterpolationEnd(input string, pos int) (int, error) {
+	depth := 0
+	for pos < len(input) {
+		switch input[pos] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				return pos, nil
+			}
+			depth--
+		case '"':
+			_, end, err := scanString(input, pos)
+			if err != nil {
+				return 0, err
+			}
+			pos = end
+			continue
+		}
+		pos++
+	}
+	return 0, fmt.Errorf("Unterminated string interpolation")
+}
@@ -0,0 +1,643 @@
+package jq
+
+import (
+	"fmt"
+)
+
+// parser is a recursive descent parser, from lowest to highest precedence:
+//
+//	pipe        := comma ( "|" pipe )?
+//	comma       := alternative ( "," alternative )*
+//	alternative := or ( "//" alternative )?
+//	or          := and ( "or" and )*
+//	and         := comparison ( "and" comparison )*
+//	comparison  := additive ( ( "==" | "!=" | "<" | "<=" | ">" | ">=" ) additive )?
+//	additive    := multiplicative ( ( "+" | "-" ) multiplicative )*
+//	multiplicative := unary ( ( "*" | "/" | "%" ) unary )*
+//	unary       := "-" unary | postfix
+//	postfix     := suffixes ( "as" $name "|" pipe )?
+//	suffixes    := term ( field | "[" ... "]" | "?" )*
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func newParser(expression string) (*parser, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	return &parser{
+		tokens: tokens,
+	}, nil
+}
+
+func (p *parser) parse() (expr, error) {
+	if p.peek().kind == tokenEOF {
+		return identityExpr{}, nil
+	}
+
+	root, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.errorf(t, "Unexpected %s", t)
+	}
+	return root, nil
+}
+
+func (p *parser) parsePipe() (expr, error) {
+	left, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+	if p.consumeOperator("|") == false {
+		return left, nil
+	}
+	right, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	return pipeExpr{left, right}, nil
+}
+
+// parsePipeNoComma is used for object values, where a comma starts the next entry.
+func (p *parser) parsePipeNoComma() (expr, error) {
+	left, err := p.parseAlternative()
+	if err != nil {
+		return nil, err
+	}
+	if p.consumeOperator("|") == false {
+		return left, nil
+	}
+	right, err := p.parsePipeNoComma()
+	if err != nil {
+		return nil, err
+	}
+	return pipeExpr{left, right}, nil
+}
+
+func (p *parser) parseComma() (expr, error) {
+	left, err := p.parseAlternative()
+	if err != nil {
+		return nil, err
+	}
+	for p.consumeOperator(",") {
+		right, err := p.parseAlternative()
+		if err != nil {
+			return nil, err
+		}
+		left = commaExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAlternative() (expr, error) {
+	left, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.consumeOperator("//") == false {
+		return left, nil
+	}
+	right, err := p.parseAlternative()
+	if err != nil {
+		return nil, err
+	}
+	return alternativeExpr{left, right}, nil
+}
+
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.consumeKeyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.consumeKeyword("and") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseComparison() (expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.consumeOperator(op) {
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			return binaryExpr{op, left, right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (expr, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek().value
+		if p.peek().kind != tokenOperator || (op != "+" && op != "-") {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op, left, right}
+	}
+}
+
+func (p *parser) parseMultiplicative() (expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek().value
+		if p.peek().kind != tokenOperator || (op != "*" && op != "/" && op != "%") {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op, left, right}
+	}
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	if p.consumeOperator("-") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return negateExpr{operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (expr, error) {
+	term, err := p.parseSuffixes()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.consumeKeyword("as") {
+		return p.parseBinding(term)
+	}
+	return term, nil
+}
+
+// parseSuffixes parses a term with all its suffixes, but without a variable binding.
+func (p *parser) parseSuffixes() (expr, error) {
+	term, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokenField:
+			p.pos++
+			term = indexExpr{term, literalExpr{t.value}}
+
+		case t.kind == tokenDot && p.peekAt(1).kind == tokenOperator && p.peekAt(1).value == "[":
+			// ".a.[0]" is the same as ".a[0]"
+			p.pos++
+
+		case t.kind == tokenOperator && t.value == "[":
+			term, err = p.parseBracketSuffix(term)
+			if err != nil {
+				return nil, err
+			}
+
+		case t.kind == tokenOperator && t.value == "?":
+			p.pos++
+			term = tryExpr{body: term}
+
+		default:
+			return term, nil
+		}
+	}
+}
+
+func (p *parser) parseBinding(source expr) (expr, error) {
+	variable := p.next()
+	if variable.kind != tokenVariable {
+		return nil, p.errorf(variable, "Expected variable after 'as', got %s", variable)
+	}
+	if err := p.expectOperator("|"); err != nil {
+		return nil, err
+	}
+	body, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	return bindExpr{source, variable.value, body}, nil
+}
+
+// parseBracketSuffix parses "[]", "[index]" and "[from:to]" after a term.
+func (p *parser) parseBracketSuffix(target expr) (expr, error) {
+	p.pos++
+
+	if p.consumeOperator("]") {
+		return iterateExpr{target}, nil
+	}
+
+	if p.consumeOperator(":") {
+		to, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectOperator("]"); err != nil {
+			return nil, err
+		}
+		return sliceExpr{target, nil, to}, nil
+	}
+
+	index, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.consumeOperator(":") {
+		var to expr
+		if p.isOperator("]") == false {
+			to, err = p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expectOperator("]"); err != nil {
+			return nil, err
+		}
+		return sliceExpr{target, index, to}, nil
+	}
+
+	if err := p.expectOperator("]"); err != nil {
+		return nil, err
+	}
+	return indexExpr{target, index}, nil
+}
+
+func (p *parser) parseTerm() (expr, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenDot:
+		return identityExpr{}, nil
+
+	case tokenRecurse:
+		return recurseExpr{}, nil
+
+	case tokenField:
+		return indexExpr{identityExpr{}, literalExpr{t.value}}, nil
+
+	case tokenNumber:
+		return literalExpr{t.number}, nil
+
+	case tokenString:
+		return newStringExpr(t.parts), nil
+
+	case tokenVariable:
+		return variableExpr{t.value}, nil
+
+	case tokenIdent:
+		return p.parseIdentTerm(t)
+
+	case tokenOperator:
+		switch t.value {
+		case "(":
+			inner, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOperator(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+
+		case "[":
+			if p.consumeOperator("]") {
+				return arrayExpr{}, nil
+			}
+			body, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectOperator("]"); err != nil {
+				return nil, err
+			}
+			return arrayExpr{body}, nil
+
+		case "{":
+			return p.parseObject()
+
+		case "|=", "+=", "-=", "*=", "/=", "%=", "//=", "=":
+			return nil, p.errorf(t, "Assignments aren't supported")
+		}
+	}
+
+	return nil, p.errorf(t, "Unexpected %s", t)
+}
+
+func (p *parser) parseIdentTerm(t token) (expr, error) {
+	switch t.value {
+	case "true":
+		return literalExpr{true}, nil
+	case "false":
+		return literalExpr{false}, nil
+	case "null":
+		return literalExpr{nil}, nil
+	case "if":
+		return p.parseIf()
+	case "reduce":
+		return p.parseReduce()
+	case "try":
+		return p.parseTry()
+	case "def", "foreach", "label", "import", "include":
+		return nil, p.errorf(t, "'%s' isn't supported", t.value)
+	case "then", "elif", "else", "end", "as", "and", "or", "catch":
+		return nil, p.errorf(t, "Unexpected keyword '%s'", t.value)
+	}
+
+	var args []expr
+	if p.consumeOperator("(") {
+		for {
+			arg, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.consumeOperator(";") {
+				continue
+			}
+			if err := p.expectOperator(")"); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	if _, exists := builtins[builtinKey(t.value, len(args))]; exists == false {
+		return nil, p.errorf(t, "%s/%d is not defined", t.value, len(args))
+	}
+	return callExpr{t.value, args}, nil
+}
+
+func (p *parser) parseIf() (expr, error) {
+	cond, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("then"); err != nil {
+		return nil, err
+	}
+	then, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+
+	var otherwise expr = identityExpr{}
+	switch {
+	case p.consumeKeyword("elif"):
+		// elif is just a nested if sharing the "end"
+		otherwise, err = p.parseIf()
+		if err != nil {
+			return nil, err
+		}
+		return ifExpr{cond, then, otherwise}, nil
+
+	case p.consumeKeyword("else"):
+		otherwise, err = p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.expectKeyword("end"); err != nil {
+		return nil, err
+	}
+	return ifExpr{cond, then, otherwise}, nil
+}
+
+func (p *parser) parseReduce() (expr, error) {
+	source, err := p.parseSuffixes()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("as"); err != nil {
+		return nil, err
+	}
+	variable := p.next()
+	if variable.kind != tokenVariable {
+		return nil, p.errorf(variable, "Expected variable after 'as', got %s", variable)
+	}
+	if err := p.expectOperator("("); err != nil {
+		return nil, err
+	}
+	init, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectOperator(";"); err != nil {
+		return nil, err
+	}
+	update, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectOperator(")"); err != nil {
+		return nil, err
+	}
+	return reduceExpr{source, variable.value, init, update}, nil
+}
+
+func (p *parser) parseTry() (expr, error) {
+	body, err := p.parseSuffixes()
+	if err != nil {
+		return nil, err
+	}
+	if p.consumeKeyword("catch") == false {
+		return tryExpr{body: body}, nil
+	}
+	handler, err := p.parseSuffixes()
+	if err != nil {
+		return nil, err
+	}
+	return tryExpr{body, handler}, nil
+}
+
+// parseObject parses the entries of an object construction, the "{" is already consumed.
+func (p *parser) parseObject() (expr, error) {
+	var entries []objectEntry
+	if p.consumeOperator("}") {
+		return objectExpr{entries}, nil
+	}
+
+	for {
+		entry, err := p.parseObjectEntry()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+
+		if p.consumeOperator(",") {
+			continue
+		}
+		if err := p.expectOperator("}"); err != nil {
+			return nil, err
+		}
+		return objectExpr{entries}, nil
+	}
+}
+
+func (p *parser) parseObjectEntry() (objectEntry, error) {
+	var entry objectEntry
+	t := p.next()
+
+	switch {
+	case t.kind == tokenVariable:
+		// {$name} is short for {name: $name}
+		entry.key = literalExpr{t.value}
+		entry.value = variableExpr{t.value}
+		if p.isOperator(":") == false {
+			return entry, nil
+		}
+
+	case t.kind == tokenIdent:
+		entry.key = literalExpr{t.value}
+		entry.value = indexExpr{identityExpr{}, literalExpr{t.value}}
+
+	case t.kind == tokenNumber:
+		entry.key = literalExpr{t.value}
+
+	case t.kind == tokenString:
+		entry.key = newStringExpr(t.parts)
+		entry.value = indexExpr{identityExpr{}, entry.key}
+
+	case t.kind == tokenOperator && t.value == "(":
+		key, err := p.parsePipe()
+		if err != nil {
+			return entry, err
+		}
+		if err := p.expectOperator(")"); err != nil {
+			return entry, err
+		}
+		entry.key = key
+
+	default:
+		return entry, p.errorf(t, "Unexpected %s in object construction", t)
+	}
+
+	if p.consumeOperator(":") == false {
+		if entry.value == nil {
+			return entry, p.errorf(p.peek(), "Expected ':' in object construction")
+		}
+		return entry, nil
+	}
+
+	value, err := p.parsePipeNoComma()
+	if err != nil {
+		return entry, err
+	}
+	entry.value = value
+	return entry, nil
+}
+
+func newStringExpr(parts []stringPart) expr {
+	if len(parts) == 1 && parts[0].expr == nil {
+		return literalExpr{parts[0].literal}
+	}
+	return stringExpr{parts}
+}
+
+func (p *parser) peek() token {
+	return p.peekAt(0)
+}
+
+func (p *parser) peekAt(offset int) token {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+offset]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	if p.pos < len(p.tokens)-1 {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isOperator(op string) bool {
+	t := p.peek()
+	return t.kind == tokenOperator && t.value == op
+}
+
+func (p *parser) consumeOperator(op string) bool {
+	if p.isOperator(op) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectOperator(op string) error {
+	if p.consumeOperator(op) {
+		return nil
+	}
+	t := p.peek()
+	return p.errorf(t, "Expected '%s', got %s", op, t)
+}
+
+func (p *parser) consumeKeyword(keyword string) bool {
+	t := p.peek()
+	if t.kind == tokenIdent && t.value == keyword {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	if p.consumeKeyword(keyword) {
+		return nil
+	}
+	t := p.peek()
+	return p.errorf(t, "Expected '%s', got %s", keyword, t)
+}
+
+func (p *parser) errorf(t token, format string, args ...interface{}) error {
+	return fmt.Errorf("%s at position %d", fmt.Sprintf(format, args...), t.pos+1)
+}
@@ -0,0 +1,418 @@
+package jq
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// isTruthy implements jq's truthiness, only false and null are falsy.
+func isTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	default:
+		return true
+	}
+}
+
+func toJSON(value interface{}) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func indexValue(target interface{}, index interface{}) (interface{}, error) {
+	switch t := target.(type) {
+	case nil:
+		switch index.(type) {
+		case string, float64, nil:
+			return nil, nil
+		}
+
+	case map[string]interface{}:
+		if key, ok := index.(string); ok {
+			return t[key], nil
+		}
+
+	case []interface{}:
+		switch i := index.(type) {
+		case float64:
+			idx := int(math.Floor(i))
+			if idx < 0 {
+				idx += len(t)
+			}
+			if idx < 0 || idx >= len(t) {
+				return nil, nil
+			}
+			return t[idx], nil
+
+		case map[string]interface{}:
+			// Objects with start/end keys are slices
+			return sliceValue(t, i["start"], i["end"])
+		}
+	}
+
+	if key, ok := index.(string); ok {
+		return nil, fmt.Errorf("Cannot index %s with \"%s\"", typeName(target), key)
+	}
+	return nil, fmt.Errorf("Cannot index %s with %s", typeName(target), typeName(index))
+}
+
+func sliceValue(target interface{}, from interface{}, to interface{}) (interface{}, error) {
+	var length int
+	switch t := target.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		length = len(t)
+	case string:
+		length = len([]rune(t))
+	default:
+		return nil, fmt.Errorf("Cannot index %s with object", typeName(target))
+	}
+
+	bound := func(value interface{}, fallback int) (int, error) {
+		if value == nil {
+			return fallback, nil
+		}
+		number, ok := value.(float64)
+		if ok == false {
+			return 0, fmt.Errorf("Start and end indices of an array slice must be numbers")
+		}
+		idx := int(math.Floor(number))
+		if idx < 0 {
+			idx += length
+		}
+		if idx < 0 {
+			idx = 0
+		}
+		if idx > length {
+			idx = length
+		}
+		return idx, nil
+	}
+
+	start, err := bound(from, 0)
+	if err != nil {
+		return nil, err
+	}
+	end, err := bound(to, length)
+	if err != nil {
+		return nil, err
+	}
+	if end < start {
+		end = start
+	}
+
+	if str, ok := target.(string); ok {
+		return string([]rune(str)[start:end]), nil
+	}
+	items := target.([]interface{})
+	sliced := make([]interface{}, end-start)
+	copy(sliced, items[start:end])
+	return sliced, nil
+}
+
+func binaryOp(op string, left interface{}, right interface{}) (interface{}, error) {
+	switch op {
+	case "+":
+		return add(left, right)
+	case "-":
+		return subtract(left, right)
+	case "*":
+		return multiply(left, right)
+	case "/":
+		return divide(left, right)
+	case "%":
+		return modulo(left, right)
+	case "==":
+		return equals(left, right), nil
+	case "!=":
+		return equals(left, right) == false, nil
+	case "<":
+		return compare(left, right) < 0, nil
+	case "<=":
+		return compare(left, right) <= 0, nil
+	case ">":
+		return compare(left, right) > 0, nil
+	case ">=":
+		return compare(left, right) >= 0, nil
+	}
+	return nil, fmt.Errorf("Unknown operator '%s'", op)
+}
+
+func add(left interface{}, right interface{}) (interface{}, error) {
+	if left == nil {
+		return right, nil
+	}
+	if right == nil {
+		return left, nil
+	}
+
+	switch l := left.(type) {
+	case float64:
+		if r, ok := right.(float64); ok {
+			return l + r, nil
+		}
+	case string:
+		if r, ok := right.(string); ok {
+			return l + r, nil
+		}
+	case []interface{}:
+		if r, ok := right.([]interface{}); ok {
+			joined := make([]interface{}, 0, len(l)+len(r))
+			return append(append(joined, l...), r...), nil
+		}
+	case map[string]interface{}:
+		if r, ok := right.(map[string]interface{}); ok {
+			merged := make(map[string]interface{}, len(l)+len(r))
+			for key, value := range l {
+				merged[key] = value
+			}
+			for key, value := range r {
+				merged[key] = value
+			}
+			return merged, nil
+		}
+	}
+	return nil, fmt.Errorf("%s and %s cannot be added", describe(left), describe(right))
+}
+
+func subtract(left interface{}, right interface{}) (interface{}, error) {
+	switch l := left.(type) {
+	case float64:
+		if r, ok := right.(float64); ok {
+			return l - r, nil
+		}
+	case []interface{}:
+		if r, ok := right.([]interface{}); ok {
+			remaining := []interface{}{}
+			for _, item := range l {
+				var found bool
+				for _, remove := range r {
+					if equals(item, remove) {
+						found = true
+						break
+					}
+				}
+				if found == false {
+					remaining = append(remaining, item)
+				}
+			}
+			return remaining, nil
+		}
+	}
+	return nil, fmt.Errorf("%s and %s cannot be subtracted", describe(left), describe(right))
+}
+
+func multiply(left interface{}, right interface{}) (interface{}, error) {
+	switch l := left.(type) {
+	case float64:
+		switch r := right.(type) {
+		case float64:
+			return l * r, nil
+		case string:
+			return repeat(r, l)
+		}
+	case string:
+		if r, ok := right.(float64); ok {
+			return repeat(l, r)
+		}
+	case map[string]interface{}:
+		if r, ok := right.(map[string]interface{}); ok {
+			return deepMerge(l, r), nil
+		}
+	}
+	return nil, fmt.Errorf("%s and %s cannot be multiplied", describe(left), describe(right))
+}
+
+// maxRepeatLength bounds the result of repeating strings, nobody needs more than a few MB.
+const maxRepeatLength = 1 << 24
+
+func repeat(str string, times float64) (interface{}, error) {
+	if times <= 0 || math.IsNaN(times) {
+		return nil, nil
+	}
+	if times > maxRepeatLength || times*float64(len(str)) > maxRepeatLength {
+		return nil, fmt.Errorf("Repeating a string %g times is too long", times)
+	}
+	return strings.Repeat(str, int(math.Ceil(times))), nil
+}
+
+func deepMerge(left map[string]interface{}, right map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(left)+len(right))
+	for key, value := range left {
+		merged[key] = value
+	}
+	for key, value := range right {
+		leftObject, leftIsObject := merged[key].(map[string]interface{})
+		rightObject, rightIsObject := value.(map[string]interface{})
+		if leftIsObject && rightIsObject {
+			merged[key] = deepMerge(leftObject, rightObject)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+func divide(left interface{}, right interface{}) (interface{}, error) {
+	switch l := left.(type) {
+	case float64:
+		if r, ok := right.(float64); ok {
+			if r == 0 {
+				return nil, fmt.Errorf("%s and %s cannot be divided because the divisor is zero", describe(left), describe(right))
+			}
+			return l / r, nil
+		}
+	case string:
+		if r, ok := right.(string); ok {
+			return splitString(l, r), nil
+		}
+	}
+	return nil, fmt.Errorf("%s and %s cannot be divided", describe(left), describe(right))
+}
+
+func modulo(left interface{}, right interface{}) (interface{}, error) {
+	l, leftOk := left.(float64)
+	r, rightOk := right.(float64)
+	if leftOk == false || rightOk == false {
+		return nil, fmt.Errorf("%s and %s cannot be divided", describe(left), describe(right))
+	}
+	if int(r) == 0 {
+		return nil, fmt.Errorf("%s and %s cannot be divided because the divisor is zero", describe(left), describe(right))
+	}
+	return float64(int(l) % int(r)), nil
+}
+
+func splitString(str string, separator string) []interface{} {
+	if len(str) == 0 {
+		return []interface{}{}
+	}
+	parts := strings.Split(str, separator)
+	items := make([]interface{}, len(parts))
+	for idx, part := range parts {
+		items[idx] = part
+	}
+	return items
+}
+
+func equals(left interface{}, right interface{}) bool {
+	return reflect.DeepEqual(left, right)
+}
+
+// typeOrder is the sort order of the types: null < false < true < numbers < strings < arrays < objects
+func typeOrder(value interface{}) int {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case bool:
+		if v {
+			return 2
+		}
+		return 1
+	case float64:
+		return 3
+	case string:
+		return 4
+	case []interface{}:
+		return 5
+	default:
+		return 6
+	}
+}
+
+// compare returns -1, 0 or 1 like jq's ordering of values.
+func compare(left interface{}, right interface{}) int {
+	leftOrder, rightOrder := typeOrder(left), typeOrder(right)
+	if leftOrder != rightOrder {
+		if leftOrder < rightOrder {
+			return -1
+		}
+		return 1
+	}
+
+	switch l := left.(type) {
+	case float64:
+		r := right.(float64)
+		switch {
+		case l < r:
+			return -1
+		case l > r:
+			return 1
+		}
+		return 0
+
+	case string:
+		return strings.Compare(l, right.(string))
+
+	case []interface{}:
+		r := right.([]interface{})
+		for idx := 0; idx < len(l) && idx < len(r); idx++ {
+			if c := compare(l[idx], r[idx]); c != 0 {
+				return c
+			}
+		}
+		return compare(float64(len(l)), float64(len(r)))
+
+	case map[string]interface{}:
+		r := right.(map[string]interface{})
+		leftKeys, rightKeys := sortedKeys(l), sortedKeys(r)
+		if c := compare(toInterfaces(leftKeys), toInterfaces(rightKeys)); c != 0 {
+			return c
+		}
+		for _, key := range leftKeys {
+			if c := compare(l[key], r[key]); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+func toInterfaces(strs []string) []interface{} {
+	items := make([]interface{}, len(strs))
+	for idx, str := range strs {
+		items[idx] = str
+	}
+	return items
+}
@@ -0,0 +1,190 @@
+package nodes
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// sparkRunes are the bar heights of sparklines, lowest first.
+var sparkRunes = []rune("▁▂▃▄▅▆▇█")
+
+// Aggregate contains the statistics of the numbers of an array.
+type Aggregate struct {
+	// Values are the numbers in document order
+	Values []float64
+
+	// Skipped is the count of elements without a finite number (at the sub-path), YAML allows .inf and .nan
+	Skipped int
+
+	Min    float64
+	Max    float64
+	Sum    float64
+	Mean   float64
+	Median float64
+
+	sorted []float64
+}
+
+// NewAggregate collects the numbers of the array elements, or the numbers at the sub-path of
+// every element if it isn't empty. At least one number is needed.
+func NewAggregate(array Node, subPath string) (*Aggregate, error) {
+	if _, isArray := array.(*arrayNode); isArray == false {
+		return nil, errors.New("Only arrays can be aggregated")
+	}
+
+	var path *jsonPath
+	if len(strings.TrimSpace(subPath)) > 0 {
+		var err error
+		path, err = parseSubPath(strings.TrimSpace(subPath))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	a := &Aggregate{}
+	for _, element := range array.Children() {
+		value := element
+		if path != nil {
+			matches := path.evaluate(element, element)
+			if len(matches) == 0 {
+				a.Skipped++
+				continue
+			}
+			value = matches[0]
+		}
+
+		number, isNumber := value.(*numberNode)
+		if isNumber == false || math.IsInf(number.value, 0) || math.IsNaN(number.value) {
+			a.Skipped++
+			continue
+		}
+		a.Values = append(a.Values, number.value)
+	}
+
+	if len(a.Values) == 0 {
+		return nil, errors.New("No numbers to aggregate")
+	}
+
+	a.sorted = append([]float64{}, a.Values...)
+	sort.Float64s(a.sorted)
+	a.Min = a.sorted[0]
+	a.Max = a.sorted[len(a.sorted)-1]
+	for _, value := range a.Values {
+		a.Sum += value
+	}
+	a.Mean = a.Sum / float64(len(a.Values))
+	a.Median = a.Percentile(50)
+	return a, nil
+}
+
+// IsNumberArray checks if the node is a non-empty array containing only numbers.
+func IsNumberArray(node Node) bool {
+	array, isArray := node.(*arrayNode)
+	if isArray == false || len(array.children) == 0 {
+		return false
+	}
+	for _, child := range array.children {
+		if _, isNumber := child.(*numberNode); isNumber == false {
+			return false
+		}
+	}
+	return true
+}
+
+// RelativePath is the jq-like path from the ancestor to the node, like ".metadata.name".
+func RelativePath(ancestor Node, node Node) string {
+	segments := pathSegments(node)
+	return buildJQPath(segments[len(pathSegments(ancestor)):], false)
+}
+
+// Count is the number of aggregated values.
+func (a *Aggregate) Count() int {
+	return len(a.Values)
+}
+
+// Percentile interpolates linearly between the closest ranks, p is between 0 and 100.
+func (a *Aggregate) Percentile(p float64) float64 {
+	sorted := a.sorted
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lower] + (sorted[lower+1]-sorted[lower])*(rank-float64(lower))
+}
+
+// Sparkline draws the values in document order, values are averaged if there are more than width.
+func (a *Aggregate) Sparkline(width int) string {
+	count := len(a.Values)
+	if width > count {
+		width = count
+	}
+
+	var sparkline strings.Builder
+	for idx := 0; idx < width; idx++ {
+		from, to := idx*count/width, (idx+1)*count/width
+		sum := 0.0
+		for _, value := range a.Values[from:to] {
+			sum += value
+		}
+		sparkline.WriteRune(a.sparkRune(sum / float64(to-from)))
+	}
+	return sparkline.String()
+}
+
+// Histogram counts the values in bins of the same width between min and max.
+func (a *Aggregate) Histogram(bins int) []int {
+	counts := make([]int, bins)
+	span := a.Max - a.Min
+	for _, value := range a.Values {
+		bin := 0
+		if span > 0 {
+			bin = int(a.scale(value) * float64(bins))
+		}
+		if bin >= bins {
+			bin = bins - 1
+		}
+		counts[bin]++
+	}
+	return counts
+}
+
+// BinBounds returns the lower and upper bound of the histogram bin.
+func (a *Aggregate) BinBounds(bin int, bins int) (float64, float64) {
+	width := (a.Max/2 - a.Min/2) / float64(bins) * 2
+	return a.Min + width*float64(bin), a.Min + width*float64(bin+1)
+}
+
+// String is a compact summary for the status bar.
+func (a *Aggregate) String() string {
+	return fmt.Sprintf("min %s, max %s, sum %s, mean %s %s", FormatNumber(a.Min), FormatNumber(a.Max), FormatNumber(a.Sum), FormatNumber(a.Mean), a.Sparkline(16))
+}
+
+// FormatNumber formats a calculated number with a precision that fits the status bar and popups.
+func FormatNumber(value float64) string {
+	return fmt.Sprintf("%.6g", value)
+}
+
+func (a *Aggregate) sparkRune(value float64) rune {
+	if a.Max <= a.Min {
+		return sparkRunes[len(sparkRunes)/2]
+	}
+	// Averages of huge numbers can still overflow
+	scaled := a.scale(value) * float64(len(sparkRunes)-1)
+	if math.IsNaN(scaled) {
+		return sparkRunes[len(sparkRunes)/2]
+	}
+	idx := int(math.Max(0, math.Min(scaled, float64(len(sparkRunes)-1))))
+	return sparkRunes[idx]
+}
+
+// scale maps the value between min and max to 0..1, halved first, so the span of huge numbers doesn't overflow.
+func (a *Aggregate) scale(value float64) float64 {
+	return (value/2 - a.Min/2) / (a.Max/2 - a.Min/2)
+}
@@ -0,0 +1,282 @@
+package nodes
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ArrangeKind is the way an arrangement transforms the elements of an array.
+type ArrangeKind string
+
+const (
+	// ArrangeSort orders the elements by the value at a sub-path, prefixed with "-" for descending
+	ArrangeSort ArrangeKind = "sort"
+
+	// ArrangeFilter keeps the elements matching a JSONPath filter expression like "@.status == 'Running'"
+	ArrangeFilter ArrangeKind = "filter"
+
+	// ArrangeGroup builds an object of arrays, grouping the elements by the value at a sub-path
+	ArrangeGroup ArrangeKind = "group"
+)
+
+// missingGroup is the key of the group of elements that don't have the value at the sub-path.
+const missingGroup = "(missing)"
+
+// Arrangement is a parsed sort, filter or group transformation of the elements of an array.
+type Arrangement struct {
+	kind       ArrangeKind
+	expression string
+	path       *jsonPath
+	filter     filterExpr
+	descending bool
+}
+
+// NewArrangement parses the expression for the kind. Sub-paths are relative to the elements,
+// so ".metadata.name", "metadata.name" and "@.metadata.name" are the same.
+func NewArrangement(kind ArrangeKind, expression string) (*Arrangement, error) {
+	expression = strings.TrimSpace(expression)
+	if len(expression) == 0 {
+		return nil, errors.New("Expression can't be empty")
+	}
+
+	a := &Arrangement{
+		kind:       kind,
+		expression: expression,
+	}
+
+	switch kind {
+	case ArrangeFilter:
+		filter, err := parseFilter(expression)
+		if err != nil {
+			return nil, err
+		}
+		a.filter = filter
+		return a, nil
+
+	case ArrangeSort:
+		if strings.HasPrefix(expression, "-") {
+			a.descending = true
+			expression = expression[1:]
+		}
+		fallthrough
+
+	case ArrangeGroup:
+		path, err := parseSubPath(expression)
+		if err != nil {
+			return nil, err
+		}
+		a.path = path
+		return a, nil
+	}
+
+	return nil, fmt.Errorf("Unknown arrangement '%s'", kind)
+}
+
+// String describes the arrangement for the status bar and the history.
+func (a *Arrangement) String() string {
+	switch a.kind {
+	case ArrangeSort:
+		return "Sort by " + a.expression
+	case ArrangeFilter:
+		return "Filter " + a.expression
+	default:
+		return "Group by " + a.expression
+	}
+}
+
+// Apply returns a copy of the array with the elements arranged, grouping returns an object of arrays.
+// The copied elements keep their source positions and decoded values.
+func (a *Arrangement) Apply(array Node) (Node, error) {
+	if _, isArray := array.(*arrayNode); isArray == false {
+		return nil, errors.New("Only arrays can be sorted, filtered or grouped")
+	}
+	elements := append([]Node{}, array.Children()...)
+
+	switch a.kind {
+	case ArrangeSort:
+		sort.SliceStable(elements, func(i, j int) bool {
+			left, right := a.valueOf(elements[i]), a.valueOf(elements[j])
+			// Missing values stay last in both directions
+			if a.descending && left != nil && right != nil {
+				return CompareValues(right, left) < 0
+			}
+			return CompareValues(left, right) < 0
+		})
+		return copyElements(array.abstract(), elements), nil
+
+	case ArrangeFilter:
+		root := array
+		for root.Parent() != nil {
+			root = root.Parent()
+		}
+		var matching []Node
+		for _, element := range elements {
+			if a.filter.eval(element, root).truthy() {
+				matching = append(matching, element)
+			}
+		}
+		return copyElements(array.abstract(), matching), nil
+
+	default:
+		var keys []string
+		groups := make(map[string][]Node)
+		for _, element := range elements {
+			key := missingGroup
+			if value := a.valueOf(element); value != nil {
+				key = CellText(value)
+			}
+			if _, exists := groups[key]; exists == false {
+				keys = append(keys, key)
+			}
+			groups[key] = append(groups[key], element)
+		}
+		sort.Strings(keys)
+
+		n := array.abstract()
+		object := &objectNode{
+			abstractNode: abstractNode{
+				key:        n.key,
+				identifier: n.identifier,
+				path:       n.path,
+				parent:     n.parent,
+				position:   n.position,
+			},
+			values: make(map[string]Node, len(keys)),
+		}
+		for _, key := range keys {
+			group := copyElements(&abstractNode{
+				key:        key,
+				identifier: key,
+				path:       jsonPathChild(n.path, key),
+				parent:     object,
+			}, groups[key])
+			object.values[key] = group
+			object.children = append(object.children, group)
+		}
+		return object, nil
+	}
+}
+
+// View builds a copy of the root with the arrangement applied to the array, the underlying
+// tree isn't changed. The copy and the arranged node in it are returned.
+func (a *Arrangement) View(root Node, array Node) (Node, Node, error) {
+	if err := checkEditable(array); err != nil {
+		return nil, nil, errors.New("Decoded values can't be arranged")
+	}
+	replacement, err := a.Apply(array)
+	if err != nil {
+		return nil, nil, err
+	}
+	if array == root {
+		return replacement, replacement, nil
+	}
+
+	view := copyNodes(root, nil)
+	target := lookupSegments(view, pathSegments(array))
+	if target == nil {
+		return nil, nil, errors.New("The array isn't part of the displayed tree")
+	}
+	replacement.abstract().parent = target.Parent()
+	replaceChild(target.Parent(), target, replacement)
+	return view, replacement, nil
+}
+
+// Arrange applies the arrangement to the array as an edit, so it's saved with the tree.
+func (t *Tree) Arrange(array Node, a *Arrangement) (Node, error) {
+	if err := checkEditable(array); err != nil {
+		return nil, err
+	}
+	replacement, err := a.Apply(array)
+	if err != nil {
+		return nil, err
+	}
+
+	t.replace(array, replacement)
+	t.record(a.String(), array, func() Node {
+		t.replace(replacement, array)
+		return array
+	}, func() Node {
+		t.replace(array, replacement)
+		return replacement
+	})
+	return replacement, nil
+}
+
+// valueOf returns the first node at the sub-path of the element, nil if there is none.
+func (a *Arrangement) valueOf(element Node) Node {
+	matches := a.path.evaluate(element, element)
+	if len(matches) == 0 {
+		return nil
+	}
+	return matches[0]
+}
+
+// parseSubPath parses a JSONPath relative to an element, with or without a leading "@", "$" or ".".
+func parseSubPath(expression string) (*jsonPath, error) {
+	expression = strings.TrimLeft(expression, "@$")
+	if len(expression) == 0 || expression == "." {
+		// The element itself, e.g. for arrays of scalars
+		return parseJSONPath("$")
+	}
+	if strings.HasPrefix(expression, ".") == false && strings.HasPrefix(expression, "[") == false {
+		expression = "." + expression
+	}
+	return parseJSONPath("$" + expression)
+}
+
+// copyElements builds an array like the template with copies of the elements.
+func copyElements(template *abstractNode, elements []Node) *arrayNode {
+	array := &arrayNode{
+		abstractNode{
+			key:        template.key,
+			identifier: template.identifier,
+			path:       template.path,
+			parent:     template.parent,
+			position:   template.position,
+			children:   make([]Node, len(elements)),
+		},
+	}
+	for idx, element := range elements {
+		array.children[idx] = copyNodes(element, array)
+	}
+	reindexElements(array)
+	return array
+}
+
+// copyNodes deep copies the node with everything rebuilding it from ToRaw would lose,
+// like the source positions, decoded values and the expansion.
+func copyNodes(node Node, parent Node) Node {
+	n := *node.abstract()
+	n.parent = parent
+	n.label = Label{}
+	n.sizeStats = nil
+	children := n.children
+	n.children = make([]Node, len(children))
+
+	var copied Node
+	switch v := node.(type) {
+	case *objectNode:
+		copied = &objectNode{abstractNode: n, values: make(map[string]Node, len(children))}
+	case *arrayNode:
+		copied = &arrayNode{n}
+	case *stringNode:
+		copied = &stringNode{n, v.value}
+	case *numberNode:
+		copied = &numberNode{n, v.value}
+	case *boolNode:
+		copied = &boolNode{n, v.value}
+	default:
+		copied = &nullNode{n}
+	}
+
+	for idx, child := range children {
+		copiedChild := copyNodes(child, copied)
+		copied.abstract().children[idx] = copiedChild
+		if object, isObject := copied.(*objectNode); isObject {
+			object.values[child.abstract().key] = copiedChild
+		}
+	}
+	return copied
+}
@@ -12,7 +12,7 @@ type arrayNode struct {
 
 func (n *arrayNode) Label() Label {
 	if len(n.label.text) > 0 || len(n.label.additionalInfo) > 0 {
-		return n.label
+		return n.changeLabel(n.label)
 	}
 
 	if n.parent == nil {
@@ -22,7 +22,7 @@ func (n *arrayNode) Label() Label {
 	}
 	n.label.additionalInfo += fmt.Sprintf("[%d]", len(n.children))
 
-	return n.label
+	return n.changeLabel(n.label)
 }
 
 func (n *arrayNode) Format(f Formatter, indentLvl int) {
@@ -0,0 +1,30 @@
+package nodes
+
+// Decoder tries to decode an encoded string value into something more readable.
+type Decoder interface {
+
+	// Name is the short name of the encoding, used for labeling the decoded node.
+	Name() string
+
+	// Decode returns the decoded value, or false if the value isn't encoded by the decoder.
+	// The returned value has to be buildable into nodes, so only JSON-compatible types are allowed.
+	Decode(value string) (interface{}, bool)
+}
+
+// decoders are tried in order, so more specific encodings must come first.
+var decoders = []Decoder{
+	decoderJWT{},
+	decoderBase64{},
+	decoderURL{},
+}
+
+// decode tries all known decoders and returns the first match.
+func decode(value string) (Decoder, interface{}, bool) {
+	for _, d := range decoders {
+		decoded, ok := d.Decode(value)
+		if ok {
+			return d, decoded, true
+		}
+	}
+	return nil, nil, false
+}
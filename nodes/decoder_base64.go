@@ -0,0 +1,68 @@
+package nodes
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minBase64Length prevents short words like "test" from being detected as base64.
+const minBase64Length = 8
+
+var base64Encodings = []*base64.Encoding{
+	base64.StdEncoding,
+	base64.RawStdEncoding,
+	base64.URLEncoding,
+	base64.RawURLEncoding,
+}
+
+type decoderBase64 struct{}
+
+func (d decoderBase64) Name() string {
+	return "base64"
+}
+
+func (d decoderBase64) Decode(value string) (interface{}, bool) {
+	value = strings.TrimSpace(value)
+	if len(value) < minBase64Length {
+		return nil, false
+	}
+
+	for _, encoding := range base64Encodings {
+		decoded, err := encoding.DecodeString(value)
+		if err != nil || isPrintable(decoded) == false {
+			continue
+		}
+		return decodeJSONOrString(string(decoded)), true
+	}
+
+	return nil, false
+}
+
+// isPrintable checks if the bytes are valid UTF-8 and only contain printable characters,
+// otherwise random strings would be detected as binary data.
+func isPrintable(data []byte) bool {
+	if len(data) == 0 || utf8.Valid(data) == false {
+		return false
+	}
+	for _, r := range string(data) {
+		if unicode.IsPrint(r) == false && unicode.IsSpace(r) == false {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeJSONOrString returns the unmarshalled JSON if possible, the plain value otherwise.
+func decodeJSONOrString(value string) interface{} {
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		var raw interface{}
+		if err := json.Unmarshal([]byte(trimmed), &raw); err == nil {
+			return raw
+		}
+	}
+	return value
+}
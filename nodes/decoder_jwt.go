@@ -0,0 +1,65 @@
+package nodes
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// jwtTimeClaims are the registered claims containing a NumericDate (RFC 7519).
+var jwtTimeClaims = []string{"exp", "iat", "nbf"}
+
+type decoderJWT struct{}
+
+func (d decoderJWT) Name() string {
+	return "jwt"
+}
+
+func (d decoderJWT) Decode(value string) (interface{}, bool) {
+	parts := strings.Split(strings.TrimSpace(value), ".")
+	if len(parts) != 3 {
+		return nil, false
+	}
+
+	header, ok := decodeJWTPart(parts[0])
+	if ok == false {
+		return nil, false
+	}
+	if _, hasAlg := header["alg"]; hasAlg == false {
+		return nil, false
+	}
+
+	payload, ok := decodeJWTPart(parts[1])
+	if ok == false {
+		return nil, false
+	}
+
+	for _, claim := range jwtTimeClaims {
+		seconds, isNumber := payload[claim].(float64)
+		if isNumber == false {
+			continue
+		}
+		payload[claim] = time.Unix(int64(seconds), 0).UTC().Format(time.RFC3339)
+	}
+
+	return map[string]interface{}{
+		"header":    header,
+		"payload":   payload,
+		"signature": parts[2],
+	}, true
+}
+
+func decodeJWTPart(part string) (map[string]interface{}, bool) {
+	// JWTs shouldn't be padded, but some implementations do it anyway
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(part, "="))
+	if err != nil {
+		return nil, false
+	}
+
+	var claims map[string]interface{}
+	if err := json.Unmarshal(decoded, &claims); err != nil {
+		return nil, false
+	}
+	return claims, true
+}
@@ -0,0 +1,97 @@
+package nodes
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var percentEncodingRegex = regexp.MustCompile(`%[0-9A-Fa-f]{2}`)
+
+type decoderURL struct{}
+
+func (d decoderURL) Name() string {
+	return "url"
+}
+
+func (d decoderURL) Decode(value string) (interface{}, bool) {
+	value = strings.TrimSpace(value)
+	if len(value) == 0 || strings.ContainsAny(value, " \t") {
+		return nil, false
+	}
+
+	if strings.Contains(value, "://") {
+		return decodeURL(value)
+	}
+
+	if strings.Contains(value, "=") {
+		if query, ok := decodeQuery(strings.TrimPrefix(value, "?")); ok {
+			return query, true
+		}
+	}
+
+	if percentEncodingRegex.MatchString(value) {
+		unescaped, err := url.PathUnescape(value)
+		if err != nil {
+			return nil, false
+		}
+		return decodeJSONOrString(unescaped), true
+	}
+
+	return nil, false
+}
+
+// decodeURL splits up a full URL, but only if it contains something worth decoding.
+func decodeURL(value string) (interface{}, bool) {
+	u, err := url.Parse(value)
+	if err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 {
+		return nil, false
+	}
+	if len(u.RawQuery) == 0 && percentEncodingRegex.MatchString(value) == false {
+		return nil, false
+	}
+
+	decoded := map[string]interface{}{
+		"scheme": u.Scheme,
+		"host":   u.Host,
+	}
+	if len(u.Path) > 0 {
+		decoded["path"] = u.Path
+	}
+	if len(u.Fragment) > 0 {
+		decoded["fragment"] = u.Fragment
+	}
+	if len(u.RawQuery) > 0 {
+		if query, ok := decodeQuery(u.RawQuery); ok {
+			decoded["query"] = query
+		} else {
+			decoded["query"] = u.RawQuery
+		}
+	}
+	return decoded, true
+}
+
+// decodeQuery parses a query string, keys with multiple values are represented as arrays.
+func decodeQuery(value string) (map[string]interface{}, bool) {
+	values, err := url.ParseQuery(value)
+	if err != nil || len(values) == 0 {
+		return nil, false
+	}
+
+	query := make(map[string]interface{}, len(values))
+	for key, params := range values {
+		if len(key) == 0 {
+			return nil, false
+		}
+		if len(params) == 1 {
+			query[key] = decodeJSONOrString(params[0])
+			continue
+		}
+		items := make([]interface{}, len(params))
+		for idx, param := range params {
+			items[idx] = decodeJSONOrString(param)
+		}
+		query[key] = items
+	}
+	return query, true
+}
@@ -0,0 +1,373 @@
+package nodes
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Change describes how a node of a diff differs between the two documents.
+type Change string
+
+const (
+	// ChangeNone means the node is identical, but might contain changes
+	ChangeNone Change = ""
+
+	// ChangeAdded nodes only exist in the second document
+	ChangeAdded Change = "added"
+
+	// ChangeRemoved nodes only exist in the first document
+	ChangeRemoved Change = "removed"
+
+	// ChangeModified nodes have a different value or type
+	ChangeModified Change = "changed"
+
+	// ChangeMoved array elements are identical, but at another position
+	ChangeMoved Change = "moved"
+
+	// ChangeConflict nodes of a merge were changed differently on both sides
+	ChangeConflict Change = "conflict"
+
+	// ChangeResolved nodes of a merge were conflicts, or were replaced with a side
+	ChangeResolved Change = "resolved"
+)
+
+// DiffStats counts the changed nodes of a diff, descendants of added/removed nodes aren't counted.
+type DiffStats struct {
+	Added    int
+	Removed  int
+	Modified int
+	Moved    int
+}
+
+func (s DiffStats) String() string {
+	return fmt.Sprintf("%d added, %d removed, %d changed, %d moved", s.Added, s.Removed, s.Modified, s.Moved)
+}
+
+// Diff builds a merged tree of both trees, every node is marked with its change.
+// Array elements are matched by the first of the keys they contain (like "name" or "id"),
+// then by equality and finally by their position.
+func Diff(left *Tree, right *Tree, keys []string) (*Tree, error) {
+	root, err := diffNodes("$", "", "", nil, left.Root(), right.Root(), keys)
+	if err != nil {
+		return nil, err
+	}
+	return &Tree{
+		fileType: right.FileType(),
+		root:     root,
+	}, nil
+}
+
+// Changed returns all nodes with a change in document order, without the descendants
+// of added or removed nodes.
+func Changed(root Node) []Node {
+	var changed []Node
+	var visit func(node Node)
+	visit = func(node Node) {
+		change := node.Change()
+		if change != ChangeNone {
+			changed = append(changed, node)
+		}
+		if change == ChangeAdded || change == ChangeRemoved {
+			return
+		}
+		for _, child := range node.Children() {
+			visit(child)
+		}
+	}
+	visit(root)
+	return changed
+}
+
+// Stats counts the changes of a diff tree.
+func Stats(root Node) DiffStats {
+	var stats DiffStats
+	for _, node := range Changed(root) {
+		switch node.Change() {
+		case ChangeAdded:
+			stats.Added++
+		case ChangeRemoved:
+			stats.Removed++
+		case ChangeModified:
+			stats.Modified++
+		case ChangeMoved:
+			stats.Moved++
+		}
+	}
+	return stats
+}
+
+func diffNodes(path string, key string, identifier string, parent Node, a Node, b Node, keys []string) (Node, error) {
+	switch {
+	case a == nil:
+		return markedCopy(path, key, identifier, parent, b, ChangeAdded, "")
+	case b == nil:
+		return markedCopy(path, key, identifier, parent, a, ChangeRemoved, "")
+	}
+
+	aObject, aIsObject := a.(*objectNode)
+	bObject, bIsObject := b.(*objectNode)
+	if aIsObject && bIsObject {
+		return diffObjects(path, key, identifier, parent, aObject, bObject, keys)
+	}
+
+	aArray, aIsArray := a.(*arrayNode)
+	bArray, bIsArray := b.(*arrayNode)
+	if aIsArray && bIsArray {
+		return diffArrays(path, key, identifier, parent, aArray, bArray, keys)
+	}
+
+	aRaw := ToRaw(a)
+	if reflect.DeepEqual(aRaw, ToRaw(b)) {
+		return markedCopy(path, key, identifier, parent, b, ChangeNone, "")
+	}
+	return markedCopy(path, key, identifier, parent, b, ChangeModified, "was "+describeRaw(aRaw))
+}
+
+func diffObjects(path string, key string, identifier string, parent Node, a *objectNode, b *objectNode, keys []string) (Node, error) {
+	merged := &objectNode{
+		abstractNode: abstractNode{
+			key:        key,
+			identifier: identifier,
+			path:       path,
+			parent:     parent,
+		},
+		values: make(map[string]Node),
+	}
+
+	for childKey := range a.values {
+		merged.values[childKey] = nil
+	}
+	for childKey := range b.values {
+		merged.values[childKey] = nil
+	}
+	childKeys := make([]string, 0, len(merged.values))
+	for childKey := range merged.values {
+		childKeys = append(childKeys, childKey)
+	}
+	sort.Strings(childKeys)
+
+	for _, childKey := range childKeys {
+		child, err := diffNodes(jsonPathChild(path, childKey), childKey, childKey, merged, a.values[childKey], b.values[childKey], keys)
+		if err != nil {
+			return nil, err
+		}
+		merged.values[childKey] = child
+		merged.children = append(merged.children, child)
+	}
+	return merged, nil
+}
+
+// diffEntry is an element of a merged array, -1 marks a missing side.
+type diffEntry struct {
+	aIdx int
+	bIdx int
+}
+
+func diffArrays(path string, key string, identifier string, parent Node, a *arrayNode, b *arrayNode, keys []string) (Node, error) {
+	entries := matchElements(a.children, b.children, keys)
+
+	merged := &arrayNode{
+		abstractNode{
+			key:        key,
+			identifier: identifier,
+			path:       path,
+			parent:     parent,
+			children:   make([]Node, len(entries)),
+		},
+	}
+
+	moved := movedElements(entries)
+	for idx, entry := range entries {
+		var aChild, bChild Node
+		if entry.aIdx >= 0 {
+			aChild = a.children[entry.aIdx]
+		}
+		if entry.bIdx >= 0 {
+			bChild = b.children[entry.bIdx]
+		}
+
+		child, err := diffNodes(jsonPathIndex(path, idx), "", fmt.Sprintf("[%d]", idx), merged, aChild, bChild, keys)
+		if err != nil {
+			return nil, err
+		}
+		if moved[entry.aIdx] {
+			n := child.abstract()
+			if n.change == ChangeNone {
+				n.change = ChangeMoved
+				n.changeInfo = fmt.Sprintf("from [%d]", entry.aIdx)
+			} else {
+				n.changeInfo += fmt.Sprintf(", moved from [%d]", entry.aIdx)
+			}
+		}
+		merged.children[idx] = child
+	}
+	return merged, nil
+}
+
+// matchElements pairs the elements of both arrays, in the order of the second array.
+// Removed elements are kept at their original index.
+func matchElements(a []Node, b []Node, keys []string) []diffEntry {
+	aRaw := make([]interface{}, len(a))
+	aKeys := make([]string, len(a))
+	for idx, element := range a {
+		aRaw[idx] = ToRaw(element)
+		aKeys[idx] = elementKey(element, keys)
+	}
+
+	matched := make([]int, len(b))
+	usedA := make([]bool, len(a))
+	bRaw := make([]interface{}, len(b))
+	bKeys := make([]string, len(b))
+	for j := range b {
+		matched[j] = -1
+		bRaw[j] = ToRaw(b[j])
+		bKeys[j] = elementKey(b[j], keys)
+	}
+
+	pair := func(accept func(i int, j int) bool) {
+		for j := range b {
+			if matched[j] >= 0 {
+				continue
+			}
+			for i := range a {
+				if usedA[i] == false && accept(i, j) {
+					matched[j] = i
+					usedA[i] = true
+					break
+				}
+			}
+		}
+	}
+
+	// Identified by key
+	pair(func(i int, j int) bool {
+		return len(bKeys[j]) > 0 && aKeys[i] == bKeys[j]
+	})
+	// Identical elements
+	pair(func(i int, j int) bool {
+		return len(aKeys[i]) == 0 && len(bKeys[j]) == 0 && reflect.DeepEqual(aRaw[i], bRaw[j])
+	})
+	// Everything else by position
+	pair(func(i int, j int) bool {
+		return len(aKeys[i]) == 0 && len(bKeys[j]) == 0
+	})
+
+	entries := make([]diffEntry, len(b))
+	for j := range b {
+		entries[j] = diffEntry{matched[j], j}
+	}
+	for i := range a {
+		if usedA[i] {
+			continue
+		}
+		pos := i
+		if pos > len(entries) {
+			pos = len(entries)
+		}
+		entries = append(entries[:pos], append([]diffEntry{{i, -1}}, entries[pos:]...)...)
+	}
+	return entries
+}
+
+// elementKey identifies an object by the value of the first key it contains.
+func elementKey(node Node, keys []string) string {
+	object, ok := node.(*objectNode)
+	if ok == false {
+		return ""
+	}
+	for _, key := range keys {
+		if value, exists := object.values[key]; exists {
+			if text, isScalar := scalarText(value); isScalar {
+				return key + "=" + text
+			}
+		}
+	}
+	return ""
+}
+
+// movedElements finds the matched elements that changed their relative order, by keeping
+// the longest increasing subsequence of the original indices in place.
+func movedElements(entries []diffEntry) map[int]bool {
+	var indices []int
+	for _, entry := range entries {
+		if entry.aIdx >= 0 && entry.bIdx >= 0 {
+			indices = append(indices, entry.aIdx)
+		}
+	}
+
+	// Patience sorting, tails contains positions in indices
+	var tails []int
+	predecessors := make([]int, len(indices))
+	for pos, value := range indices {
+		idx := sort.Search(len(tails), func(i int) bool {
+			return indices[tails[i]] >= value
+		})
+		predecessors[pos] = -1
+		if idx > 0 {
+			predecessors[pos] = tails[idx-1]
+		}
+		if idx == len(tails) {
+			tails = append(tails, pos)
+		} else {
+			tails[idx] = pos
+		}
+	}
+
+	inOrder := make(map[int]bool, len(tails))
+	if len(tails) > 0 {
+		for pos := tails[len(tails)-1]; pos >= 0; pos = predecessors[pos] {
+			inOrder[indices[pos]] = true
+		}
+	}
+
+	moved := make(map[int]bool)
+	for _, value := range indices {
+		if inOrder[value] == false {
+			moved[value] = true
+		}
+	}
+	return moved
+}
+
+// markedCopy rebuilds the node and marks it with the change, added and removed nodes
+// mark all their descendants, too.
+func markedCopy(path string, key string, identifier string, parent Node, source Node, change Change, info string) (Node, error) {
+	node, err := buildNodes(path, key, identifier, parent, ToRaw(source))
+	if err != nil {
+		return nil, err
+	}
+
+	var mark func(n Node)
+	mark = func(n Node) {
+		n.abstract().change = change
+		if change != ChangeAdded && change != ChangeRemoved {
+			return
+		}
+		for _, child := range n.Children() {
+			mark(child)
+		}
+	}
+	mark(node)
+	node.abstract().changeInfo = info
+	return node, nil
+}
+
+// describeRaw returns a short description of a value for change infos.
+func describeRaw(raw interface{}) string {
+	switch raw.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	}
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return "?"
+	}
+	if len(encoded) > 30 {
+		return string(encoded[:27]) + "..."
+	}
+	return string(encoded)
+}
@@ -0,0 +1,112 @@
+package nodes
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DuplicateGroup contains identical subtrees, in document order.
+type DuplicateGroup struct {
+	Nodes []Node
+
+	// Size is the serialized size of a single subtree
+	Size int
+}
+
+// Wasted is the size of all copies except the first, what deduplication could save.
+func (g DuplicateGroup) Wasted() int {
+	return g.Size * (len(g.Nodes) - 1)
+}
+
+// FindDuplicates hashes all objects and arrays structurally and groups identical subtrees, the
+// most wasted bytes first. Members with one of the ignored keys don't count, and key order never
+// matters, because objects don't keep it. Duplicates nested in other duplicates aren't listed again.
+func FindDuplicates(root Node, ignoreKeys []string) []DuplicateGroup {
+	ignored := make(map[string]bool, len(ignoreKeys))
+	for _, key := range ignoreKeys {
+		ignored[key] = true
+	}
+
+	hashes := make(map[Node]string)
+	var order []string
+	byHash := make(map[string][]Node)
+	var walk func(node Node) string
+	walk = func(node Node) string {
+		var canonical strings.Builder
+		switch n := node.(type) {
+		case *objectNode:
+			canonical.WriteString("{")
+			for _, member := range n.children {
+				key := member.abstract().key
+				hash := walk(member)
+				if ignored[key] {
+					continue
+				}
+				canonical.WriteString(strconv.Quote(key) + ":" + hash + ",")
+			}
+			canonical.WriteString("}")
+
+		case *arrayNode:
+			canonical.WriteString("[")
+			for _, child := range n.children {
+				canonical.WriteString(walk(child) + ",")
+			}
+			canonical.WriteString("]")
+
+		default:
+			_, valueType, _ := EditableValue(node)
+			text, _ := scalarText(node)
+			return string(valueType) + ":" + strconv.Quote(text)
+		}
+
+		hash := fmt.Sprintf("%x", sha1.Sum([]byte(canonical.String())))
+		if len(node.Children()) > 0 {
+			hashes[node] = hash
+			if _, exists := byHash[hash]; exists == false {
+				order = append(order, hash)
+			}
+			byHash[hash] = append(byHash[hash], node)
+		}
+		return hash
+	}
+	walk(root)
+
+	var groups []DuplicateGroup
+	for _, hash := range order {
+		duplicates := byHash[hash]
+		if len(duplicates) < 2 || nestedDuplicates(duplicates, hashes, byHash) {
+			continue
+		}
+		groups = append(groups, DuplicateGroup{
+			Nodes: sortByDocumentOrder(root, duplicates),
+			Size:  Measure(duplicates[0]).Size,
+		})
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Wasted() > groups[j].Wasted()
+	})
+	return groups
+}
+
+// nestedDuplicates checks if all duplicates are the children of the same group of duplicates,
+// the parent group already shows them.
+func nestedDuplicates(duplicates []Node, hashes map[Node]string, byHash map[string][]Node) bool {
+	parent := duplicates[0].Parent()
+	if parent == nil {
+		return false
+	}
+	parentHash, isHashed := hashes[parent]
+	if isHashed == false || len(byHash[parentHash]) != len(duplicates) {
+		return false
+	}
+	for _, duplicate := range duplicates {
+		if duplicate.Parent() == nil || hashes[duplicate.Parent()] != parentHash {
+			return false
+		}
+	}
+	return true
+}
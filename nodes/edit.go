@@ -0,0 +1,451 @@
+package nodes
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/benweidig/trex/input"
+)
+
+// ValueType is the type of a value entered by the user.
+type ValueType string
+
+const (
+	// ValueTypeString is a plain string
+	ValueTypeString ValueType = "string"
+
+	// ValueTypeNumber is a floating point number
+	ValueTypeNumber ValueType = "number"
+
+	// ValueTypeBoolean is either true or false
+	ValueTypeBoolean ValueType = "boolean"
+
+	// ValueTypeNull ignores the entered text
+	ValueTypeNull ValueType = "null"
+
+	// ValueTypeObject creates an empty object
+	ValueTypeObject ValueType = "object"
+
+	// ValueTypeArray creates an empty array
+	ValueTypeArray ValueType = "array"
+)
+
+// ValueTypes contains all value types in the order they are cycled through.
+var ValueTypes = []ValueType{
+	ValueTypeString,
+	ValueTypeNumber,
+	ValueTypeBoolean,
+	ValueTypeNull,
+	ValueTypeObject,
+	ValueTypeArray,
+}
+
+// ParseValue converts the entered text to a value of the type.
+func ParseValue(text string, valueType ValueType) (interface{}, error) {
+	switch valueType {
+	case ValueTypeNumber:
+		number, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a number", text)
+		}
+		return number, nil
+
+	case ValueTypeBoolean:
+		value, err := strconv.ParseBool(strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a boolean, use true or false", text)
+		}
+		return value, nil
+
+	case ValueTypeNull:
+		return nil, nil
+
+	case ValueTypeObject:
+		return map[string]interface{}{}, nil
+
+	case ValueTypeArray:
+		return []interface{}{}, nil
+
+	default:
+		return text, nil
+	}
+}
+
+// EditableValue returns the text and type of a scalar node for editing.
+func EditableValue(node Node) (string, ValueType, bool) {
+	switch n := node.(type) {
+	case *stringNode:
+		return n.value, ValueTypeString, true
+	case *numberNode:
+		return strconv.FormatFloat(n.value, 'f', -1, 64), ValueTypeNumber, true
+	case *boolNode:
+		return strconv.FormatBool(n.value), ValueTypeBoolean, true
+	case *nullNode:
+		return "", ValueTypeNull, true
+	default:
+		return "", "", false
+	}
+}
+
+// EditableKey returns the key of an object member for renaming.
+func EditableKey(node Node) (string, bool) {
+	if _, ok := node.Parent().(*objectNode); ok == false {
+		return "", false
+	}
+	return node.abstract().key, true
+}
+
+// AddTarget returns the node new children are added to, objects and arrays themselves
+// and the parent for all other nodes. The bool reports whether the child needs a key.
+func AddTarget(node Node) (Node, bool) {
+	switch node.(type) {
+	case *objectNode:
+		return node, true
+	case *arrayNode:
+		return node, false
+	}
+	if parent := node.Parent(); parent != nil {
+		return AddTarget(parent)
+	}
+	return node, false
+}
+
+// History returns the recorded changes of the tree.
+func (t *Tree) History() *History {
+	return &t.history
+}
+
+// IsDirty reports whether the tree was edited since it was loaded or saved.
+func (t *Tree) IsDirty() bool {
+	return t.history.isDirty()
+}
+
+// MarkSaved resets the dirty state and updates the positions of the saved content.
+func (t *Tree) MarkSaved(positions input.Positions) {
+	t.history.markSaved()
+	locateNodes(t.root, "", positions)
+}
+
+// SetValue replaces a scalar node with a new value and returns the new node.
+func (t *Tree) SetValue(node Node, value interface{}) (Node, error) {
+	if _, _, ok := EditableValue(node); ok == false {
+		return nil, errors.New("Only scalar values can be edited")
+	}
+	if err := checkEditable(node); err != nil {
+		return nil, err
+	}
+
+	n := node.abstract()
+	replacement, err := buildNodes(n.path, n.key, n.identifier, n.parent, value)
+	if err != nil {
+		return nil, err
+	}
+	replacement.abstract().position = n.position
+
+	t.replace(node, replacement)
+	t.record("Set value", node, func() Node {
+		t.replace(replacement, node)
+		return node
+	}, func() Node {
+		t.replace(node, replacement)
+		return replacement
+	})
+	return replacement, nil
+}
+
+// RenameKey changes the key of an object member.
+func (t *Tree) RenameKey(node Node, key string) error {
+	if err := checkEditable(node); err != nil {
+		return err
+	}
+	parent, ok := node.Parent().(*objectNode)
+	if ok == false {
+		return errors.New("Only object members have a key")
+	}
+	oldKey := node.abstract().key
+	if key == oldKey {
+		return nil
+	}
+	if err := checkNewKey(parent, key); err != nil {
+		return err
+	}
+
+	t.record("Rename key", node, func() Node {
+		renameMember(parent, node, oldKey)
+		return node
+	}, func() Node {
+		renameMember(parent, node, key)
+		return node
+	})
+	renameMember(parent, node, key)
+	return nil
+}
+
+// Add creates a new child of an object (with the key) or appends it to an array.
+func (t *Tree) Add(parent Node, key string, value interface{}) (Node, error) {
+	return t.add("Add", parent, key, value)
+}
+
+// Delete removes the node from its parent.
+func (t *Tree) Delete(node Node) error {
+	if err := checkEditable(node); err != nil {
+		return err
+	}
+	parent := node.Parent()
+	if parent == nil {
+		return errors.New("The root can't be deleted")
+	}
+
+	idx := indexOf(parent.Children(), node)
+	t.record("Delete", node, func() Node {
+		insertChild(parent, node, idx)
+		return node
+	}, func() Node {
+		removeChild(parent, node)
+		return parent
+	})
+	removeChild(parent, node)
+	return nil
+}
+
+// Duplicate inserts a copy of the node after it, object members get a new key.
+func (t *Tree) Duplicate(node Node) (Node, error) {
+	if err := checkEditable(node); err != nil {
+		return nil, err
+	}
+
+	switch p := node.Parent().(type) {
+	case *objectNode:
+		key := node.abstract().key + " copy"
+		for count := 2; p.values[key] != nil; count++ {
+			key = fmt.Sprintf("%s copy %d", node.abstract().key, count)
+		}
+		return t.add("Duplicate", p, key, ToRaw(node))
+
+	case *arrayNode:
+		idx := indexOf(p.children, node) + 1
+		copied, err := buildNodes("", "", "", p, ToRaw(node))
+		if err != nil {
+			return nil, err
+		}
+		insertChild(p, copied, idx)
+		t.record("Duplicate", copied, func() Node {
+			removeChild(p, copied)
+			return node
+		}, func() Node {
+			insertChild(p, copied, idx)
+			return copied
+		})
+		return copied, nil
+
+	default:
+		return nil, errors.New("Only object members and array elements can be duplicated")
+	}
+}
+
+// Move swaps an array element with its previous (negative offset) or next sibling.
+func (t *Tree) Move(node Node, offset int) error {
+	if err := checkEditable(node); err != nil {
+		return err
+	}
+	p, ok := node.Parent().(*arrayNode)
+	if ok == false {
+		return errors.New("Only array elements can be moved, object keys are sorted")
+	}
+
+	idx := indexOf(p.children, node)
+	target := idx + offset
+	if target < 0 || target >= len(p.children) {
+		return nil
+	}
+
+	swap := func() Node {
+		p.children[idx], p.children[target] = p.children[target], p.children[idx]
+		reindexElements(p)
+		return node
+	}
+	swap()
+	t.record("Move", node, swap, swap)
+	return nil
+}
+
+func (t *Tree) add(description string, parent Node, key string, value interface{}) (Node, error) {
+	if err := checkEditable(parent); err != nil {
+		return nil, err
+	}
+
+	var idx int
+	switch p := parent.(type) {
+	case *objectNode:
+		if err := checkNewKey(p, key); err != nil {
+			return nil, err
+		}
+	case *arrayNode:
+		idx = len(p.children)
+	default:
+		return nil, errors.New("Children can only be added to objects and arrays")
+	}
+
+	child, err := buildNodes("", key, key, parent, value)
+	if err != nil {
+		return nil, err
+	}
+	insertChild(parent, child, idx)
+	t.record(description, child, func() Node {
+		removeChild(parent, child)
+		return parent
+	}, func() Node {
+		insertChild(parent, child, idx)
+		return child
+	})
+	return child, nil
+}
+
+// replace swaps the node with the replacement in its parent, or as root.
+func (t *Tree) replace(node Node, replacement Node) {
+	if parent := node.Parent(); parent != nil {
+		replaceChild(parent, node, replacement)
+	} else {
+		t.root = replacement
+	}
+}
+
+// record adds an applied modification of the node to the history.
+func (t *Tree) record(description string, node Node, undo func() Node, redo func() Node) {
+	t.history.Record(Command{
+		Description: description,
+		Path:        node.Path(),
+		undo:        undo,
+		redo:        redo,
+		modifies:    true,
+	})
+}
+
+// checkEditable prevents changes of decoded values, see isDecoded.
+func checkEditable(node Node) error {
+	if isDecoded(node) {
+		return errors.New("Decoded values can't be edited")
+	}
+	return nil
+}
+
+func checkNewKey(object *objectNode, key string) error {
+	if len(key) == 0 {
+		return errors.New("Keys can't be empty")
+	}
+	if _, exists := object.values[key]; exists {
+		return fmt.Errorf("Key '%s' already exists", key)
+	}
+	return nil
+}
+
+func indexOf(children []Node, node Node) int {
+	for idx, child := range children {
+		if child == node {
+			return idx
+		}
+	}
+	return -1
+}
+
+// sortMembers restores the sorted order of the children of an object.
+func sortMembers(object *objectNode) {
+	sort.Slice(object.children, func(i, j int) bool {
+		return object.children[i].abstract().key < object.children[j].abstract().key
+	})
+	object.label = Label{}
+}
+
+// reindexElements updates the paths of all elements after the order of an array changed.
+func reindexElements(array *arrayNode) {
+	for idx, child := range array.children {
+		reindex(child, jsonPathIndex(array.path, idx), fmt.Sprintf("[%d]", idx))
+	}
+	array.label = Label{}
+}
+
+func replaceChild(parent Node, old Node, replacement Node) {
+	invalidateSizes(parent)
+	p := parent.abstract()
+	for idx, child := range p.children {
+		if child == old {
+			p.children[idx] = replacement
+		}
+	}
+	if object, ok := parent.(*objectNode); ok {
+		object.values[old.abstract().key] = replacement
+	}
+}
+
+// renameMember changes the key of an object member, keeping the members sorted.
+func renameMember(object *objectNode, node Node, key string) {
+	invalidateSizes(object)
+	n := node.abstract()
+	delete(object.values, n.key)
+	n.key = key
+	object.values[key] = node
+	reindex(node, jsonPathChild(object.path, key), key)
+	sortMembers(object)
+}
+
+// insertChild adds the node to an object, or at the index of an array.
+func insertChild(parent Node, node Node, idx int) {
+	invalidateSizes(parent)
+	switch p := parent.(type) {
+	case *objectNode:
+		key := node.abstract().key
+		p.values[key] = node
+		p.children = append(p.children, node)
+		reindex(node, jsonPathChild(p.path, key), key)
+		sortMembers(p)
+
+	case *arrayNode:
+		p.children = append(p.children, nil)
+		copy(p.children[idx+1:], p.children[idx:])
+		p.children[idx] = node
+		reindexElements(p)
+	}
+}
+
+func removeChild(parent Node, old Node) {
+	invalidateSizes(parent)
+	p := parent.abstract()
+	if idx := indexOf(p.children, old); idx >= 0 {
+		p.children = append(p.children[:idx], p.children[idx+1:]...)
+	}
+
+	switch n := parent.(type) {
+	case *objectNode:
+		delete(n.values, old.abstract().key)
+		n.label = Label{}
+	case *arrayNode:
+		// The following elements moved up, so their indices changed
+		reindexElements(n)
+	}
+}
+
+// reindex updates the path of the node and its descendants.
+func reindex(node Node, path string, identifier string) {
+	n := node.abstract()
+	n.path = path
+	n.identifier = identifier
+	n.label = Label{}
+
+	switch node.(type) {
+	case *objectNode:
+		for _, child := range n.children {
+			reindex(child, jsonPathChild(path, child.abstract().key), child.abstract().identifier)
+		}
+	case *arrayNode:
+		for idx, child := range n.children {
+			reindex(child, jsonPathIndex(path, idx), fmt.Sprintf("[%d]", idx))
+		}
+	case *stringNode:
+		for _, child := range n.children {
+			reindex(child, path+child.abstract().identifier, child.abstract().identifier)
+		}
+	}
+}
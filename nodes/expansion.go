@@ -0,0 +1,32 @@
+package nodes
+
+// SetExpansion expands or collapses the node and all its descendants.
+func SetExpansion(node Node, expanded bool) {
+	setCollapsed(node, expanded == false)
+	for _, child := range node.Children() {
+		SetExpansion(child, expanded)
+	}
+}
+
+// ExpandToDepth expands the nodes above the depth and collapses all others, so the root and
+// depth levels below it are visible. A depth of 0 or less expands everything.
+func ExpandToDepth(root Node, depth int) {
+	if depth <= 0 {
+		SetExpansion(root, true)
+		return
+	}
+	expandToDepth(root, depth)
+}
+
+func expandToDepth(node Node, depth int) {
+	setCollapsed(node, depth <= 0)
+	for _, child := range node.Children() {
+		expandToDepth(child, depth-1)
+	}
+}
+
+func setCollapsed(node Node, collapsed bool) {
+	if node.IsCollapsable() && node.IsCollapsed() != collapsed {
+		node.ToggleExpansion()
+	}
+}
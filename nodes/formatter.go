@@ -1,6 +1,9 @@
 package nodes
 
 import (
+	"math"
+	"strconv"
+
 	"github.com/benweidig/trex/input"
 )
 
@@ -40,3 +43,23 @@ func BuildFormatter(indentWidth int, monochrome bool, fileType input.FileType) F
 		panic("No formatter for '" + string(fileType) + "'")
 	}
 }
+
+// Serialize writes the node as a document of the file type, without colors and with a trailing newline,
+// unknown file types are written as JSON.
+func Serialize(node Node, fileType input.FileType) []byte {
+	if fileType == input.FileTypeUnknown {
+		fileType = input.FileTypeJSON
+	}
+	f := BuildFormatter(2, true, fileType)
+	node.Format(f, 1)
+	return []byte(f.String() + "\n")
+}
+
+// formatNumber writes integers without an exponent, so large IDs stay readable and exact,
+// all other numbers in their shortest representation.
+func formatNumber(value float64) string {
+	if value == math.Trunc(value) && math.Abs(value) < 1e21 {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
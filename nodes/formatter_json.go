@@ -1,7 +1,6 @@
 package nodes
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
 
@@ -50,14 +49,13 @@ func (f *formatterJSON) writeDelimiter(n Node) Formatter {
 }
 
 func (f *formatterJSON) writeKey(key string, n Node) Formatter {
+	quoted := quoteJSONString(key)
 	if f.monochrome {
-		f.builder.WriteString("\"")
-		f.builder.WriteString(key)
-		f.builder.WriteString("\"")
+		f.builder.WriteString(quoted)
 	} else {
-		f.builder.WriteString("[lightskyblue]\"")
-		f.builder.WriteString(tview.Escape(key))
-		f.builder.WriteString("\"[-]")
+		f.builder.WriteString("[lightskyblue]")
+		f.builder.WriteString(tview.Escape(quoted))
+		f.builder.WriteString("[-]")
 	}
 	return f
 }
@@ -73,9 +71,9 @@ func (f *formatterJSON) writeKeyValueSeparator(n Node) Formatter {
 
 func (f *formatterJSON) writeNumber(value float64, n Node) Formatter {
 	if f.monochrome {
-		f.builder.WriteString(fmt.Sprintf("%g", value))
+		f.builder.WriteString(formatNumber(value))
 	} else {
-		f.builder.WriteString(fmt.Sprintf("[darkseagreen]%g[-]", value))
+		f.builder.WriteString("[darkseagreen]" + formatNumber(value) + "[-]")
 	}
 	return f
 }
@@ -92,14 +90,13 @@ func (f *formatterJSON) writeBoolean(value bool, n Node) Formatter {
 }
 
 func (f *formatterJSON) writeString(value string, n Node) Formatter {
+	quoted := quoteJSONString(value)
 	if f.monochrome {
-		f.builder.WriteString("\"")
-		f.builder.WriteString(value)
-		f.builder.WriteString("\"")
+		f.builder.WriteString(quoted)
 	} else {
-		f.builder.WriteString("[sandybrown]\"")
-		f.builder.WriteString(tview.Escape(value))
-		f.builder.WriteString("\"[-]")
+		f.builder.WriteString("[sandybrown]")
+		f.builder.WriteString(tview.Escape(quoted))
+		f.builder.WriteString("[-]")
 	}
 	return f
 }
@@ -118,11 +115,19 @@ func (f *formatterJSON) writeArrayItemIndicator(n Node) Formatter {
 }
 
 func (f *formatterJSON) writeArrayStart(n Node) Formatter {
+	if len(n.Children()) == 0 {
+		f.builder.WriteString("[")
+		return f
+	}
 	f.builder.WriteString("[\n")
 	return f
 }
 
 func (f *formatterJSON) writeArrayEnd(indentLvl int, n Node) Formatter {
+	if len(n.Children()) == 0 {
+		f.builder.WriteString("]")
+		return f
+	}
 	f.builder.WriteString("\n")
 	f.writeIndention(indentLvl, n)
 	f.builder.WriteString("]")
@@ -130,10 +135,18 @@ func (f *formatterJSON) writeArrayEnd(indentLvl int, n Node) Formatter {
 }
 
 func (f *formatterJSON) writeObjectStart(n Node) Formatter {
+	if len(n.Children()) == 0 {
+		f.builder.WriteString("{")
+		return f
+	}
 	f.builder.WriteString("{\n")
 	return f
 }
 func (f *formatterJSON) writeObjectEnd(indentLvl int, n Node) Formatter {
+	if len(n.Children()) == 0 {
+		f.builder.WriteString("}")
+		return f
+	}
 	f.builder.WriteString("\n")
 	f.writeIndention(indentLvl, n)
 	f.builder.WriteString("}")
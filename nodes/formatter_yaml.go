@@ -1,13 +1,22 @@
 package nodes
 
 import (
-	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/rivo/tview"
 )
 
+// yamlPlainKeyRegex matches keys that can be written without quotes.
+var yamlPlainKeyRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_./-]*$`)
+
+// yamlReservedKeys would be read as booleans or null without quotes.
+var yamlReservedKeys = map[string]bool{
+	"y": true, "n": true, "yes": true, "no": true, "on": true, "off": true,
+	"true": true, "false": true, "null": true,
+}
+
 type formatterYAML struct {
 	builder        strings.Builder
 	indentWidth    int
@@ -51,29 +60,19 @@ func (f *formatterYAML) writeDelimiter(n Node) Formatter {
 }
 
 func (f *formatterYAML) writeKey(key string, n Node) Formatter {
+	if yamlPlainKeyRegex.MatchString(key) == false || yamlReservedKeys[strings.ToLower(key)] {
+		key = quoteJSONString(key)
+	}
 	if f.monochrome {
-		if strings.Contains(key, " ") {
-			f.builder.WriteString("'")
-			f.builder.WriteString(key)
-			f.builder.WriteString("'")
-			return f
-		}
 		f.builder.WriteString(key)
 	} else {
 		f.builder.WriteString("[lightskyblue]")
-		if strings.Contains(key, " ") {
-			f.builder.WriteString("'")
-			f.builder.WriteString(tview.Escape(key))
-			f.builder.WriteString("'")
-			f.builder.WriteString("[-]")
-			return f
-		}
 		f.builder.WriteString(tview.Escape(key))
 		f.builder.WriteString("[-]")
-
 	}
 	return f
 }
+
 func (f *formatterYAML) writeKeyValueSeparator(n Node) Formatter {
 	f.builder.WriteString(": ")
 	return f
@@ -81,9 +80,9 @@ func (f *formatterYAML) writeKeyValueSeparator(n Node) Formatter {
 
 func (f *formatterYAML) writeNumber(value float64, n Node) Formatter {
 	if f.monochrome {
-		f.builder.WriteString(fmt.Sprintf("%g", value))
+		f.builder.WriteString(formatNumber(value))
 	} else {
-		f.builder.WriteString(fmt.Sprintf("[darkseagreen]%g[-]", value))
+		f.builder.WriteString("[darkseagreen]" + formatNumber(value) + "[-]")
 	}
 	return f
 }
@@ -98,14 +97,13 @@ func (f *formatterYAML) writeBoolean(value bool, n Node) Formatter {
 	return f
 }
 func (f *formatterYAML) writeString(value string, n Node) Formatter {
+	quoted := quoteJSONString(value)
 	if f.monochrome {
-		f.builder.WriteString("\"")
-		f.builder.WriteString(value)
-		f.builder.WriteString("\"")
+		f.builder.WriteString(quoted)
 	} else {
-		f.builder.WriteString("[sandybrown]\"")
-		f.builder.WriteString(tview.Escape(value))
-		f.builder.WriteString("\"[-]")
+		f.builder.WriteString("[sandybrown]")
+		f.builder.WriteString(tview.Escape(quoted))
+		f.builder.WriteString("[-]")
 	}
 	return f
 }
@@ -123,6 +121,11 @@ func (f *formatterYAML) writeArrayItemIndicator(n Node) Formatter {
 	return f
 }
 func (f *formatterYAML) writeArrayStart(n Node) Formatter {
+	// Without the flow style, empty containers would be read as null
+	if len(n.Children()) == 0 {
+		f.builder.WriteString("[]")
+		return f
+	}
 	if f.builder.Len() == 0 {
 		return f
 	}
@@ -134,6 +137,10 @@ func (f *formatterYAML) writeArrayEnd(indentLvl int, n Node) Formatter {
 }
 
 func (f *formatterYAML) writeObjectStart(n Node) Formatter {
+	if len(n.Children()) == 0 {
+		f.builder.WriteString("{}")
+		return f
+	}
 	if f.builder.Len() == 0 {
 		return f
 	}
@@ -0,0 +1,173 @@
+package nodes
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// FuzzyMatch is a node whose path matches a fuzzy query.
+type FuzzyMatch struct {
+	Node Node
+	Path string
+
+	// Positions are the rune indices of the matched characters in the path
+	Positions []int
+
+	score int
+}
+
+// fuzzyLimit caps the results, nobody scrolls through thousands of paths
+const fuzzyLimit = 200
+
+// FuzzyIndex holds the paths of all nodes below (and including) a root, collapsed or not.
+// Building the paths is the expensive part, so it's done once and every query only scores them.
+type FuzzyIndex struct {
+	nodes []Node
+	paths []string
+	lower [][]rune
+}
+
+// NewFuzzyIndex collects the paths of the root and its descendants, decoded values are skipped,
+// see isDecoded.
+func NewFuzzyIndex(root Node, syntax PathSyntax) *FuzzyIndex {
+	index := &FuzzyIndex{}
+	var visit func(node Node)
+	visit = func(node Node) {
+		path := BuildPath(node, syntax)
+		lower := []rune(path)
+		for idx, r := range lower {
+			lower[idx] = unicode.ToLower(r)
+		}
+		index.nodes = append(index.nodes, node)
+		index.paths = append(index.paths, path)
+		index.lower = append(index.lower, lower)
+		for _, child := range structuralChildren(node) {
+			visit(child)
+		}
+	}
+	visit(root)
+	return index
+}
+
+// Find matches the query against the indexed paths. The query consists of whitespace separated
+// terms, every term must match a subsequence of the path. The best matches come first.
+func (i *FuzzyIndex) Find(query string) []FuzzyMatch {
+	terms := strings.Fields(strings.ToLower(query))
+
+	var matches []FuzzyMatch
+	for idx, node := range i.nodes {
+		if match, ok := fuzzyMatch(i.paths[idx], i.lower[idx], terms); ok {
+			match.Node = node
+			matches = append(matches, match)
+		}
+	}
+
+	// Document order is kept for equal scores
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return len(matches[i].Path) < len(matches[j].Path)
+	})
+
+	if len(matches) > fuzzyLimit {
+		matches = matches[:fuzzyLimit]
+	}
+	return matches
+}
+
+func fuzzyMatch(path string, lower []rune, terms []string) (FuzzyMatch, bool) {
+	match := FuzzyMatch{
+		Path: path,
+	}
+	for _, term := range terms {
+		score, positions, ok := fuzzyScore(lower, []rune(term))
+		if ok == false {
+			return match, false
+		}
+		match.score += score
+		match.Positions = append(match.Positions, positions...)
+	}
+
+	// Shorter paths are more specific
+	match.score -= len(lower) / 4
+	sort.Ints(match.Positions)
+	return match, true
+}
+
+// fuzzyScore prefers contiguous matches, matches at the start of a path segment
+// and matches in the last segment, like fzf does.
+func fuzzyScore(text []rune, term []rune) (int, []int, bool) {
+	lastSegment := 0
+	for idx, r := range text {
+		if isPathSeparator(r) {
+			lastSegment = idx + 1
+		}
+	}
+
+	bestScore := -1
+	var bestPositions []int
+
+	// Contiguous matches
+	for start := 0; start+len(term) <= len(text); start++ {
+		if string(text[start:start+len(term)]) != string(term) {
+			continue
+		}
+		score := 100 + 10*len(term)
+		if isSegmentStart(text, start) {
+			score += 30
+		}
+		if start >= lastSegment {
+			score += 20
+		}
+		if score > bestScore {
+			bestScore = score
+			bestPositions = make([]int, len(term))
+			for idx := range term {
+				bestPositions[idx] = start + idx
+			}
+		}
+	}
+	if bestScore >= 0 {
+		return bestScore, bestPositions, true
+	}
+
+	// Scattered matches, found greedily
+	var positions []int
+	score := 0
+	pos := 0
+	for _, r := range term {
+		for pos < len(text) && text[pos] != r {
+			pos++
+		}
+		if pos == len(text) {
+			return 0, nil, false
+		}
+
+		score += 5
+		switch {
+		case len(positions) > 0 && positions[len(positions)-1] == pos-1:
+			score += 5
+		case isSegmentStart(text, pos):
+			score += 10
+		case len(positions) > 0:
+			score -= pos - positions[len(positions)-1]
+		}
+		positions = append(positions, pos)
+		pos++
+	}
+	return score, positions, true
+}
+
+func isSegmentStart(text []rune, idx int) bool {
+	if idx == 0 {
+		return true
+	}
+	prev := text[idx-1]
+	return isPathSeparator(prev) || prev == '_' || prev == '-' || prev == '"' || prev == '\''
+}
+
+func isPathSeparator(r rune) bool {
+	return r == '.' || r == '[' || r == ']' || r == '/'
+}
@@ -0,0 +1,102 @@
+package nodes
+
+import "errors"
+
+// Command is a recorded change that can be undone and redone.
+type Command struct {
+	// Description names the kind of change, like "Delete"
+	Description string
+
+	// Path is the path of the affected node at the time of the change
+	Path string
+
+	undo     func() Node
+	redo     func() Node
+	modifies bool
+}
+
+// NewCommand creates a command for changes that don't modify the document, like filters.
+// The functions return the node to select afterwards, nil if they update the display themselves.
+func NewCommand(description string, path string, undo func() Node, redo func() Node) Command {
+	return Command{
+		Description: description,
+		Path:        path,
+		undo:        undo,
+		redo:        redo,
+	}
+}
+
+// History records the commands of a tree, so they can be undone and redone in order.
+type History struct {
+	commands []Command
+	applied  int
+	saved    int
+}
+
+// Record adds an already applied command, all undone commands are dropped.
+func (h *History) Record(c Command) {
+	if h.saved > h.applied {
+		// The saved state can't be reached anymore, unless the dropped commands didn't modify anything
+		if h.modifies(h.applied, h.saved) {
+			h.saved = -1
+		} else {
+			h.saved = h.applied
+		}
+	}
+
+	h.commands = append(h.commands[:h.applied], c)
+	h.applied++
+}
+
+// Undo reverts the last applied command and returns the node to select.
+func (h *History) Undo() (Node, error) {
+	if h.applied == 0 {
+		return nil, errors.New("Nothing to undo")
+	}
+	h.applied--
+	return h.commands[h.applied].undo(), nil
+}
+
+// Redo applies the last undone command again and returns the node to select.
+func (h *History) Redo() (Node, error) {
+	if h.applied == len(h.commands) {
+		return nil, errors.New("Nothing to redo")
+	}
+	h.applied++
+	return h.commands[h.applied-1].redo(), nil
+}
+
+// Commands returns all recorded commands, including the undone ones.
+func (h *History) Commands() []Command {
+	return h.commands
+}
+
+// Applied is the count of commands that are currently applied, the rest was undone.
+func (h *History) Applied() int {
+	return h.applied
+}
+
+// isDirty reports whether the document differs from the last saved state.
+func (h *History) isDirty() bool {
+	if h.saved < 0 {
+		return true
+	}
+	if h.saved < h.applied {
+		return h.modifies(h.saved, h.applied)
+	}
+	return h.modifies(h.applied, h.saved)
+}
+
+func (h *History) markSaved() {
+	h.saved = h.applied
+}
+
+// modifies reports whether any of the commands in the range modifies the document.
+func (h *History) modifies(from int, to int) bool {
+	for _, c := range h.commands[from:to] {
+		if c.modifies {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,240 @@
+package nodes
+
+import (
+	"strconv"
+)
+
+// jsonPath is a parsed JSONPath expression (http://goessner.net/articles/JsonPath/).
+type jsonPath struct {
+	segments []jsonPathSegment
+}
+
+// jsonPathSegment is a single step of a JSONPath, selecting children (or all descendants) of nodes.
+type jsonPathSegment struct {
+	descendant bool
+	selectors  []jsonPathSelector
+}
+
+type jsonPathSelectorKind int
+
+const (
+	selectorName jsonPathSelectorKind = iota
+	selectorWildcard
+	selectorIndex
+	selectorSlice
+	selectorFilter
+)
+
+type jsonPathSelector struct {
+	kind jsonPathSelectorKind
+
+	name  string
+	index int
+
+	// slices use pointers, because omitted values have different defaults depending on the step
+	start *int
+	end   *int
+	step  int
+
+	filter filterExpr
+}
+
+// QueryJSONPath evaluates a JSONPath expression and returns all matching nodes in document order.
+// Decoded values of string nodes are never matched, see isDecoded.
+func QueryJSONPath(root Node, expression string) ([]Node, error) {
+	path, err := parseJSONPath(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := path.evaluate(root, root)
+
+	// Unions and descendants might select the same node multiple times
+	unique := make([]Node, 0, len(matches))
+	seen := make(map[Node]bool, len(matches))
+	for _, match := range matches {
+		if seen[match] {
+			continue
+		}
+		seen[match] = true
+		unique = append(unique, match)
+	}
+
+	return sortByDocumentOrder(root, unique), nil
+}
+
+func (p *jsonPath) evaluate(current Node, root Node) []Node {
+	selected := []Node{current}
+	for _, segment := range p.segments {
+		var next []Node
+		for _, node := range selected {
+			candidates := []Node{node}
+			if segment.descendant {
+				candidates = appendDescendants(candidates, node)
+			}
+			for _, candidate := range candidates {
+				for _, selector := range segment.selectors {
+					next = selector.apply(candidate, root, next)
+				}
+			}
+		}
+		selected = next
+	}
+	return selected
+}
+
+func (s jsonPathSelector) apply(node Node, root Node, selected []Node) []Node {
+	switch s.kind {
+	case selectorName:
+		if object, ok := node.(*objectNode); ok {
+			if child, exists := object.values[s.name]; exists {
+				selected = append(selected, child)
+			}
+		}
+
+	case selectorWildcard:
+		selected = append(selected, structuralChildren(node)...)
+
+	case selectorIndex:
+		if array, ok := node.(*arrayNode); ok {
+			idx := s.index
+			if idx < 0 {
+				idx += len(array.children)
+			}
+			if idx >= 0 && idx < len(array.children) {
+				selected = append(selected, array.children[idx])
+			}
+		}
+
+	case selectorSlice:
+		if array, ok := node.(*arrayNode); ok {
+			for _, idx := range s.sliceIndices(len(array.children)) {
+				selected = append(selected, array.children[idx])
+			}
+		}
+
+	case selectorFilter:
+		for _, child := range structuralChildren(node) {
+			if s.filter.eval(child, root).truthy() {
+				selected = append(selected, child)
+			}
+		}
+	}
+	return selected
+}
+
+// sliceIndices calculates the selected indices like Python does (RFC 9535, 2.3.4.2.2).
+func (s jsonPathSelector) sliceIndices(length int) []int {
+	if s.step == 0 {
+		return nil
+	}
+
+	normalize := func(idx int) int {
+		if idx < 0 {
+			return idx + length
+		}
+		return idx
+	}
+	clamp := func(idx int, lower int, upper int) int {
+		if idx < lower {
+			return lower
+		}
+		if idx > upper {
+			return upper
+		}
+		return idx
+	}
+
+	var indices []int
+	if s.step > 0 {
+		start, end := 0, length
+		if s.start != nil {
+			start = clamp(normalize(*s.start), 0, length)
+		}
+		if s.end != nil {
+			end = clamp(normalize(*s.end), 0, length)
+		}
+		for idx := start; idx < end; idx += s.step {
+			indices = append(indices, idx)
+		}
+		return indices
+	}
+
+	start, end := length-1, -1
+	if s.start != nil {
+		start = clamp(normalize(*s.start), -1, length-1)
+	}
+	if s.end != nil {
+		end = clamp(normalize(*s.end), -1, length-1)
+	}
+	for idx := start; idx > end; idx += s.step {
+		indices = append(indices, idx)
+	}
+	return indices
+}
+
+// structuralChildren are the children of objects and arrays, without decoded values.
+func structuralChildren(node Node) []Node {
+	switch node.(type) {
+	case *objectNode, *arrayNode:
+		return node.Children()
+	default:
+		return nil
+	}
+}
+
+// appendDescendants appends all structural descendants in document order.
+func appendDescendants(descendants []Node, node Node) []Node {
+	for _, child := range structuralChildren(node) {
+		descendants = append(descendants, child)
+		descendants = appendDescendants(descendants, child)
+	}
+	return descendants
+}
+
+// sortByDocumentOrder sorts the nodes by their appearance in the tree.
+func sortByDocumentOrder(root Node, nodes []Node) []Node {
+	if len(nodes) < 2 {
+		return nodes
+	}
+
+	wanted := make(map[Node]bool, len(nodes))
+	for _, node := range nodes {
+		wanted[node] = true
+	}
+
+	sorted := make([]Node, 0, len(nodes))
+	for _, node := range appendDescendants([]Node{root}, root) {
+		if wanted[node] {
+			sorted = append(sorted, node)
+		}
+	}
+	return sorted
+}
+
+// scalarValue returns the Go value of scalar nodes, containers are returned as they are.
+func scalarValue(node Node) interface{} {
+	switch n := node.(type) {
+	case *stringNode:
+		return n.value
+	case *numberNode:
+		return n.value
+	case *boolNode:
+		return n.value
+	case *nullNode:
+		return nil
+	default:
+		return node
+	}
+}
+
+func intPtr(value string) (*int, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
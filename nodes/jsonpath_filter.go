@@ -0,0 +1,388 @@
+package nodes
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// filterExpr is a node of a parsed filter expression like "@.price < 10 && @.isbn".
+type filterExpr interface {
+	eval(current Node, root Node) filterValue
+}
+
+// filterValue is the result of a filter expression. Paths without any match are "nothing",
+// which is only equal to another "nothing".
+type filterValue struct {
+	value   interface{}
+	nothing bool
+	isPath  bool
+}
+
+func (v filterValue) truthy() bool {
+	if v.isPath {
+		return v.nothing == false
+	}
+	b, ok := v.value.(bool)
+	return ok && b
+}
+
+type filterOr struct{ left, right filterExpr }
+
+func (e filterOr) eval(current Node, root Node) filterValue {
+	return filterValue{value: e.left.eval(current, root).truthy() || e.right.eval(current, root).truthy()}
+}
+
+type filterAnd struct{ left, right filterExpr }
+
+func (e filterAnd) eval(current Node, root Node) filterValue {
+	return filterValue{value: e.left.eval(current, root).truthy() && e.right.eval(current, root).truthy()}
+}
+
+type filterNot struct{ expr filterExpr }
+
+func (e filterNot) eval(current Node, root Node) filterValue {
+	return filterValue{value: e.expr.eval(current, root).truthy() == false}
+}
+
+type filterLiteral struct{ value interface{} }
+
+func (e filterLiteral) eval(current Node, root Node) filterValue {
+	return filterValue{value: e.value}
+}
+
+type filterPath struct {
+	relative bool
+	path     *jsonPath
+}
+
+func (e filterPath) eval(current Node, root Node) filterValue {
+	start := root
+	if e.relative {
+		start = current
+	}
+	matches := e.path.evaluate(start, root)
+	if len(matches) == 0 {
+		return filterValue{nothing: true, isPath: true}
+	}
+	if len(matches) > 1 {
+		// Only singular paths are comparable, but they still exist
+		return filterValue{value: matches, isPath: true}
+	}
+	return filterValue{value: scalarValue(matches[0]), isPath: true}
+}
+
+type filterComparison struct {
+	op    string
+	left  filterExpr
+	right filterExpr
+}
+
+func (e filterComparison) eval(current Node, root Node) filterValue {
+	left := e.left.eval(current, root)
+	right := e.right.eval(current, root)
+
+	switch e.op {
+	case "==":
+		return filterValue{value: filterEqual(left, right)}
+	case "!=":
+		return filterValue{value: filterEqual(left, right) == false}
+	case "<":
+		return filterValue{value: filterLess(left, right)}
+	case "<=":
+		return filterValue{value: filterLess(left, right) || filterEqual(left, right)}
+	case ">":
+		return filterValue{value: filterLess(right, left)}
+	case ">=":
+		return filterValue{value: filterLess(right, left) || filterEqual(left, right)}
+	}
+	return filterValue{value: false}
+}
+
+type filterRegex struct {
+	left  filterExpr
+	regex *regexp.Regexp
+}
+
+func (e filterRegex) eval(current Node, root Node) filterValue {
+	value, ok := e.left.eval(current, root).value.(string)
+	return filterValue{value: ok && e.regex.MatchString(value)}
+}
+
+func filterEqual(left filterValue, right filterValue) bool {
+	if left.nothing || right.nothing {
+		return left.nothing && right.nothing
+	}
+	if _, isSlice := left.value.([]Node); isSlice {
+		return false
+	}
+	if _, isSlice := right.value.([]Node); isSlice {
+		return false
+	}
+	return left.value == right.value
+}
+
+func filterLess(left filterValue, right filterValue) bool {
+	switch l := left.value.(type) {
+	case float64:
+		r, ok := right.value.(float64)
+		return ok && l < r
+	case string:
+		r, ok := right.value.(string)
+		return ok && l < r
+	}
+	return false
+}
+
+// filterParser is a recursive descent parser for filter expressions:
+//
+//	or         := and ( "||" and )*
+//	and        := unary ( "&&" unary )*
+//	unary      := "!" unary | comparison
+//	comparison := operand ( ( "==" | "!=" | "<" | "<=" | ">" | ">=" | "=~" ) operand )?
+//	operand    := "(" or ")" | path | string | number | regex | true | false | null
+type filterParser struct {
+	input string
+	pos   int
+}
+
+func parseFilter(expression string) (filterExpr, error) {
+	p := &filterParser{
+		input: expression,
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipWhitespace()
+	if p.pos < len(p.input) {
+		return nil, p.errorf("Unexpected '%s'", p.input[p.pos:])
+	}
+	return expr, nil
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = filterOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = filterAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterExpr, error) {
+	p.skipWhitespace()
+	if strings.HasPrefix(p.input[p.pos:], "!=") == false && p.consume("!") {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return filterNot{expr}, nil
+	}
+	return p.parseComparison()
+}
+
+var filterOperators = []string{"==", "!=", "<=", ">=", "=~", "<", ">"}
+
+func (p *filterParser) parseComparison() (filterExpr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipWhitespace()
+	for _, op := range filterOperators {
+		if p.consume(op) == false {
+			continue
+		}
+
+		if op == "=~" {
+			regex, err := p.parseRegexOperand()
+			if err != nil {
+				return nil, err
+			}
+			return filterRegex{left, regex}, nil
+		}
+
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return filterComparison{op, left, right}, nil
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseOperand() (filterExpr, error) {
+	p.skipWhitespace()
+	if p.pos >= len(p.input) {
+		return nil, p.errorf("Unexpected end of filter")
+	}
+
+	switch c := p.input[p.pos]; {
+	case c == '(':
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.consume(")") == false {
+			return nil, p.errorf("Missing ')'")
+		}
+		return expr, nil
+
+	case c == '@' || c == '$':
+		return p.parsePath()
+
+	case c == '\'' || c == '"':
+		value, end, err := scanQuoted(p.input, p.pos)
+		if err != nil {
+			return nil, p.errorf("%s", err.Error())
+		}
+		p.pos = end
+		return filterLiteral{value}, nil
+
+	case c == '-' || unicode.IsDigit(rune(c)):
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.input) && strings.ContainsRune("0123456789.eE+-", rune(p.input[p.pos])) {
+			p.pos++
+		}
+		number, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+		if err != nil {
+			return nil, p.errorf("Invalid number '%s'", p.input[start:p.pos])
+		}
+		return filterLiteral{number}, nil
+	}
+
+	for keyword, value := range map[string]interface{}{"true": true, "false": false, "null": nil} {
+		if p.consume(keyword) {
+			return filterLiteral{value}, nil
+		}
+	}
+
+	return nil, p.errorf("Unexpected '%c'", p.input[p.pos])
+}
+
+// parsePath reads a path until the next operator or whitespace outside of brackets.
+func (p *filterParser) parsePath() (filterExpr, error) {
+	relative := p.input[p.pos] == '@'
+	start := p.pos
+	depth := 0
+
+loop:
+	for p.pos < len(p.input) {
+		switch c := p.input[p.pos]; {
+		case c == '\'' || c == '"':
+			_, end, err := scanQuoted(p.input, p.pos)
+			if err != nil {
+				return nil, p.errorf("%s", err.Error())
+			}
+			p.pos = end
+			continue
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case depth > 0:
+		case unicode.IsSpace(rune(c)) || strings.ContainsRune("=!<>&|()", rune(c)):
+			break loop
+		}
+		p.pos++
+	}
+
+	raw := p.input[start:p.pos]
+	path, err := parseJSONPath("$" + raw[1:])
+	if err != nil {
+		return nil, err
+	}
+	return filterPath{relative, path}, nil
+}
+
+// parseRegexOperand parses a regex literal like /^abc/i or a string containing a regex.
+func (p *filterParser) parseRegexOperand() (*regexp.Regexp, error) {
+	p.skipWhitespace()
+	if p.pos >= len(p.input) {
+		return nil, p.errorf("Missing regular expression")
+	}
+
+	var pattern string
+	switch p.input[p.pos] {
+	case '/':
+		end := p.pos + 1
+		for end < len(p.input) && p.input[end] != '/' {
+			if p.input[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.input) {
+			return nil, p.errorf("Unterminated regular expression")
+		}
+		pattern = p.input[p.pos+1 : end]
+		p.pos = end + 1
+		if p.pos < len(p.input) && p.input[p.pos] == 'i' {
+			pattern = "(?i)" + pattern
+			p.pos++
+		}
+
+	case '\'', '"':
+		value, end, err := scanQuoted(p.input, p.pos)
+		if err != nil {
+			return nil, p.errorf("%s", err.Error())
+		}
+		pattern = value
+		p.pos = end
+
+	default:
+		return nil, p.errorf("Expected regular expression")
+	}
+
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, p.errorf("Invalid regular expression: %s", err.Error())
+	}
+	return regex, nil
+}
+
+func (p *filterParser) consume(token string) bool {
+	p.skipWhitespace()
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) skipWhitespace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *filterParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("Filter: %s at position %d", fmt.Sprintf(format, args...), p.pos+1)
+}
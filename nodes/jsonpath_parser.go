@@ -0,0 +1,277 @@
+package nodes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type jsonPathParser struct {
+	input string
+	pos   int
+}
+
+// parseJSONPath parses an expression like "$.store..book[?(@.price < 10)].title".
+// The leading "$" is optional.
+func parseJSONPath(expression string) (*jsonPath, error) {
+	p := &jsonPathParser{
+		input: strings.TrimSpace(expression),
+	}
+	if len(p.input) == 0 {
+		return nil, fmt.Errorf("Empty JSONPath")
+	}
+
+	if p.peek() == '$' {
+		p.pos++
+	}
+
+	path := &jsonPath{}
+	for p.pos < len(p.input) {
+		segment, err := p.parseSegment()
+		if err != nil {
+			return nil, err
+		}
+		path.segments = append(path.segments, segment)
+	}
+	return path, nil
+}
+
+func (p *jsonPathParser) parseSegment() (jsonPathSegment, error) {
+	var segment jsonPathSegment
+
+	switch p.peek() {
+	case '.':
+		p.pos++
+		if p.peek() == '.' {
+			p.pos++
+			segment.descendant = true
+			if p.peek() == '[' {
+				return p.parseBracketSegment(segment)
+			}
+		}
+		selector, err := p.parseDotMember()
+		if err != nil {
+			return segment, err
+		}
+		segment.selectors = []jsonPathSelector{selector}
+		return segment, nil
+
+	case '[':
+		return p.parseBracketSegment(segment)
+
+	default:
+		if p.pos == 0 {
+			// Allow omitting the "$." prefix, like "store.book"
+			selector, err := p.parseDotMember()
+			if err != nil {
+				return segment, err
+			}
+			segment.selectors = []jsonPathSelector{selector}
+			return segment, nil
+		}
+		return segment, p.errorf("Unexpected character '%c'", p.peek())
+	}
+}
+
+func (p *jsonPathParser) parseDotMember() (jsonPathSelector, error) {
+	if p.peek() == '*' {
+		p.pos++
+		return jsonPathSelector{kind: selectorWildcard}, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) {
+		r := rune(p.input[p.pos])
+		if r == '.' || r == '[' || unicode.IsSpace(r) {
+			break
+		}
+		p.pos++
+	}
+	if p.pos == start {
+		return jsonPathSelector{}, p.errorf("Missing member name")
+	}
+	return jsonPathSelector{kind: selectorName, name: p.input[start:p.pos]}, nil
+}
+
+func (p *jsonPathParser) parseBracketSegment(segment jsonPathSegment) (jsonPathSegment, error) {
+	// skip '['
+	p.pos++
+
+	for {
+		p.skipWhitespace()
+		selector, err := p.parseBracketSelector()
+		if err != nil {
+			return segment, err
+		}
+		segment.selectors = append(segment.selectors, selector)
+
+		p.skipWhitespace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return segment, nil
+		default:
+			return segment, p.errorf("Expected ',' or ']'")
+		}
+	}
+}
+
+func (p *jsonPathParser) parseBracketSelector() (jsonPathSelector, error) {
+	switch c := p.peek(); {
+	case c == '*':
+		p.pos++
+		return jsonPathSelector{kind: selectorWildcard}, nil
+
+	case c == '\'' || c == '"':
+		name, err := p.parseQuoted()
+		if err != nil {
+			return jsonPathSelector{}, err
+		}
+		return jsonPathSelector{kind: selectorName, name: name}, nil
+
+	case c == '?':
+		p.pos++
+		start := p.pos
+		end, err := p.findSelectorEnd()
+		if err != nil {
+			return jsonPathSelector{}, err
+		}
+		p.pos = end
+		filter, err := parseFilter(p.input[start:end])
+		if err != nil {
+			return jsonPathSelector{}, err
+		}
+		return jsonPathSelector{kind: selectorFilter, filter: filter}, nil
+
+	case c == '(':
+		return jsonPathSelector{}, p.errorf("Script expressions aren't supported, use filters '[?(...)]' instead")
+
+	default:
+		start := p.pos
+		for p.pos < len(p.input) && p.peek() != ',' && p.peek() != ']' {
+			p.pos++
+		}
+		return p.parseIndexOrSlice(strings.TrimSpace(p.input[start:p.pos]))
+	}
+}
+
+func (p *jsonPathParser) parseIndexOrSlice(value string) (jsonPathSelector, error) {
+	if strings.Contains(value, ":") == false {
+		idx, err := strconv.Atoi(value)
+		if err != nil {
+			return jsonPathSelector{}, p.errorf("Invalid index '%s'", value)
+		}
+		return jsonPathSelector{kind: selectorIndex, index: idx}, nil
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) > 3 {
+		return jsonPathSelector{}, p.errorf("Invalid slice '%s'", value)
+	}
+
+	selector := jsonPathSelector{kind: selectorSlice, step: 1}
+	var err error
+	if selector.start, err = intPtr(strings.TrimSpace(parts[0])); err != nil {
+		return selector, p.errorf("Invalid slice start '%s'", parts[0])
+	}
+	if selector.end, err = intPtr(strings.TrimSpace(parts[1])); err != nil {
+		return selector, p.errorf("Invalid slice end '%s'", parts[1])
+	}
+	if len(parts) == 3 {
+		step, err := intPtr(strings.TrimSpace(parts[2]))
+		if err != nil {
+			return selector, p.errorf("Invalid slice step '%s'", parts[2])
+		}
+		if step != nil {
+			selector.step = *step
+		}
+	}
+	return selector, nil
+}
+
+// parseQuoted parses a single- or double-quoted string with backslash escapes.
+func (p *jsonPathParser) parseQuoted() (string, error) {
+	value, end, err := scanQuoted(p.input, p.pos)
+	if err != nil {
+		return "", p.errorf("%s", err.Error())
+	}
+	p.pos = end
+	return value, nil
+}
+
+// findSelectorEnd finds the ',' or ']' ending a filter selector, skipping nested brackets and strings.
+func (p *jsonPathParser) findSelectorEnd() (int, error) {
+	depth := 0
+	for pos := p.pos; pos < len(p.input); pos++ {
+		switch c := p.input[pos]; c {
+		case '\'', '"':
+			_, end, err := scanQuoted(p.input, pos)
+			if err != nil {
+				return 0, err
+			}
+			pos = end - 1
+		case '(', '[':
+			depth++
+		case ')':
+			depth--
+		case ']':
+			if depth == 0 {
+				return pos, nil
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				return pos, nil
+			}
+		}
+	}
+	return 0, p.errorf("Missing ']'")
+}
+
+func (p *jsonPathParser) skipWhitespace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *jsonPathParser) peek() byte {
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *jsonPathParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s at position %d", fmt.Sprintf(format, args...), p.pos+1)
+}
+
+// scanQuoted reads the quoted string starting at pos and returns its value and the position after it.
+func scanQuoted(input string, pos int) (string, int, error) {
+	quote := input[pos]
+	var value strings.Builder
+	for idx := pos + 1; idx < len(input); idx++ {
+		c := input[idx]
+		switch {
+		case c == '\\' && idx+1 < len(input):
+			idx++
+			switch escaped := input[idx]; escaped {
+			case 'n':
+				value.WriteByte('\n')
+			case 't':
+				value.WriteByte('\t')
+			case 'r':
+				value.WriteByte('\r')
+			default:
+				value.WriteByte(escaped)
+			}
+		case c == quote:
+			return value.String(), idx + 1, nil
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("Unterminated string")
+}
@@ -1,13 +1,31 @@
 package nodes
 
+import "github.com/rivo/tview"
+
 // Label represents all info needed to print a nice node label
 type Label struct {
 	text           string
 	additionalInfo string
+	change         Change
+	changeInfo     string
+}
+
+// changeColors are the colors of the text and marker of changed nodes
+var changeColors = map[Change]string{
+	ChangeAdded:    "green",
+	ChangeRemoved:  "red",
+	ChangeModified: "yellow",
+	ChangeMoved:    "cornflowerblue",
+	ChangeConflict: "fuchsia",
+	ChangeResolved: "lightseagreen",
 }
 
 func (l Label) String(monochrome bool) string {
 	label := l.text
+	color, changed := changeColors[l.change]
+	if changed && monochrome == false {
+		label = "[" + color + "]" + label + "[-]"
+	}
 	if len(l.additionalInfo) > 0 {
 		if len(l.text) > 0 {
 			label += " "
@@ -18,5 +36,24 @@ func (l Label) String(monochrome bool) string {
 			label += "[gray]" + l.additionalInfo + "[-]"
 		}
 	}
+	if changed {
+		marker := string(l.change)
+		if len(l.changeInfo) > 0 {
+			marker += ": " + l.changeInfo
+		}
+		marker = tview.Escape("(" + marker + ")")
+		if monochrome {
+			label += " " + marker
+		} else {
+			label += " [" + color + "]" + marker + "[-]"
+		}
+	}
 	return label
 }
+
+// Highlighted returns a copy of the label with all matches in the text highlighted,
+// the additional info isn't searched.
+func (l Label) Highlighted(m *Matcher) Label {
+	l.text = m.Highlight(l.text)
+	return l
+}
@@ -0,0 +1,277 @@
+package nodes
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// MergeSide is one of the versions of a three-way merge.
+type MergeSide string
+
+const (
+	// MergeSideBase is the common ancestor
+	MergeSideBase MergeSide = "base"
+
+	// MergeSideOurs is the local version
+	MergeSideOurs MergeSide = "ours"
+
+	// MergeSideTheirs is the version being merged in
+	MergeSideTheirs MergeSide = "theirs"
+)
+
+// Merge is a structural three-way merge. Nodes changed differently on both sides are
+// marked as conflicts and can be resolved by picking one of the versions.
+type Merge struct {
+	tree    *Tree
+	sides   map[MergeSide]*Tree
+	written bool
+}
+
+// NewMerge merges the changes of ours and theirs compared to base. Objects are merged by key,
+// arrays only element-wise if all versions have the same length.
+func NewMerge(base *Tree, ours *Tree, theirs *Tree) (*Merge, error) {
+	root, err := mergeNodes("$", "", "", nil, base.Root(), ours.Root(), theirs.Root())
+	if err != nil {
+		return nil, err
+	}
+	if root == nil {
+		// Can't happen, every document has a root, so at least one side has a value
+		return nil, errors.New("Merge result is empty")
+	}
+
+	return &Merge{
+		tree: &Tree{
+			fileType: ours.FileType(),
+			root:     root,
+		},
+		sides: map[MergeSide]*Tree{
+			MergeSideBase:   base,
+			MergeSideOurs:   ours,
+			MergeSideTheirs: theirs,
+		},
+	}, nil
+}
+
+// Tree returns the merged tree.
+func (m *Merge) Tree() *Tree {
+	return m.tree
+}
+
+// IsDirty reports whether the result wasn't written yet, or was changed since.
+func (m *Merge) IsDirty() bool {
+	return m.written == false || m.tree.IsDirty()
+}
+
+// MarkWritten resets the dirty state after the result was written.
+func (m *Merge) MarkWritten() {
+	m.written = true
+	m.tree.history.markSaved()
+}
+
+// Conflicts returns all unresolved conflicts in document order.
+func (m *Merge) Conflicts() []Node {
+	var conflicts []Node
+	for _, node := range Changed(m.tree.root) {
+		if node.Change() == ChangeConflict {
+			conflicts = append(conflicts, node)
+		}
+	}
+	return conflicts
+}
+
+// Resolve replaces the node with the version of the side at the same path.
+// The replacement is returned, or nil if the node doesn't exist on that side and was removed.
+func (m *Merge) Resolve(node Node, side MergeSide) (Node, error) {
+	n := node.abstract()
+	if isDecoded(node) {
+		return nil, errors.New("Decoded values can't be resolved")
+	}
+
+	source := lookupSegments(m.sides[side].Root(), pathSegments(node))
+	if source == nil {
+		if n.parent == nil {
+			return nil, errors.New("The root can't be removed")
+		}
+		parent := n.parent
+		idx := indexOf(parent.Children(), node)
+		m.tree.record("Resolve with "+string(side), node, func() Node {
+			insertChild(parent, node, idx)
+			return node
+		}, func() Node {
+			removeChild(parent, node)
+			return parent
+		})
+		removeChild(parent, node)
+		return nil, nil
+	}
+
+	resolved, err := buildNodes(n.path, n.key, n.identifier, n.parent, ToRaw(source))
+	if err != nil {
+		return nil, err
+	}
+	resolved.abstract().change = ChangeResolved
+	resolved.abstract().changeInfo = string(side)
+
+	m.tree.replace(node, resolved)
+	m.tree.record("Resolve with "+string(side), node, func() Node {
+		m.tree.replace(resolved, node)
+		return node
+	}, func() Node {
+		m.tree.replace(node, resolved)
+		return resolved
+	})
+	return resolved, nil
+}
+
+func mergeNodes(path string, key string, identifier string, parent Node, base Node, ours Node, theirs Node) (Node, error) {
+	switch {
+	case sameNodes(ours, theirs), sameNodes(base, theirs):
+		return copyNode(path, key, identifier, parent, ours)
+	case sameNodes(base, ours):
+		return copyNode(path, key, identifier, parent, theirs)
+	}
+
+	// Changed on both sides, maybe the changes don't overlap
+	oursObject, oursIsObject := ours.(*objectNode)
+	theirsObject, theirsIsObject := theirs.(*objectNode)
+	baseObject, baseIsObject := base.(*objectNode)
+	if oursIsObject && theirsIsObject && (base == nil || baseIsObject) {
+		return mergeObjects(path, key, identifier, parent, baseObject, oursObject, theirsObject)
+	}
+
+	oursArray, oursIsArray := ours.(*arrayNode)
+	theirsArray, theirsIsArray := theirs.(*arrayNode)
+	baseArray, baseIsArray := base.(*arrayNode)
+	if oursIsArray && theirsIsArray && baseIsArray &&
+		len(oursArray.children) == len(baseArray.children) && len(theirsArray.children) == len(baseArray.children) {
+		return mergeArrays(path, key, identifier, parent, baseArray, oursArray, theirsArray)
+	}
+
+	display := ours
+	if display == nil {
+		display = theirs
+	}
+	conflict, err := copyNode(path, key, identifier, parent, display)
+	if err != nil {
+		return nil, err
+	}
+	conflict.abstract().change = ChangeConflict
+	conflict.abstract().changeInfo = fmt.Sprintf("ours %s, theirs %s", describeVersion(ours), describeVersion(theirs))
+	return conflict, nil
+}
+
+func mergeObjects(path string, key string, identifier string, parent Node, base *objectNode, ours *objectNode, theirs *objectNode) (Node, error) {
+	merged := &objectNode{
+		abstractNode: abstractNode{
+			key:        key,
+			identifier: identifier,
+			path:       path,
+			parent:     parent,
+		},
+		values: make(map[string]Node),
+	}
+
+	var baseValues map[string]Node
+	if base != nil {
+		baseValues = base.values
+	}
+	childKeys := make(map[string]bool)
+	for _, values := range []map[string]Node{baseValues, ours.values, theirs.values} {
+		for childKey := range values {
+			childKeys[childKey] = true
+		}
+	}
+
+	for _, childKey := range sortedKeys(childKeys) {
+		child, err := mergeNodes(jsonPathChild(path, childKey), childKey, childKey, merged, baseValues[childKey], ours.values[childKey], theirs.values[childKey])
+		if err != nil {
+			return nil, err
+		}
+		if child == nil {
+			continue
+		}
+		merged.values[childKey] = child
+		merged.children = append(merged.children, child)
+	}
+	return merged, nil
+}
+
+func mergeArrays(path string, key string, identifier string, parent Node, base *arrayNode, ours *arrayNode, theirs *arrayNode) (Node, error) {
+	merged := &arrayNode{
+		abstractNode{
+			key:        key,
+			identifier: identifier,
+			path:       path,
+			parent:     parent,
+			children:   make([]Node, len(base.children)),
+		},
+	}
+
+	for idx := range base.children {
+		child, err := mergeNodes(jsonPathIndex(path, idx), "", fmt.Sprintf("[%d]", idx), merged, base.children[idx], ours.children[idx], theirs.children[idx])
+		if err != nil {
+			return nil, err
+		}
+		merged.children[idx] = child
+	}
+	return merged, nil
+}
+
+// sameNodes compares the values of nodes, nil means the node doesn't exist.
+func sameNodes(a Node, b Node) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return reflect.DeepEqual(ToRaw(a), ToRaw(b))
+}
+
+// copyNode rebuilds the node for the merged tree, nil stays nil.
+func copyNode(path string, key string, identifier string, parent Node, source Node) (Node, error) {
+	if source == nil {
+		return nil, nil
+	}
+	return buildNodes(path, key, identifier, parent, ToRaw(source))
+}
+
+func describeVersion(node Node) string {
+	if node == nil {
+		return "deleted"
+	}
+	return describeRaw(ToRaw(node))
+}
+
+func sortedKeys(keys map[string]bool) []string {
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// lookupSegments finds the node at the path, nil if it doesn't exist.
+func lookupSegments(root Node, segments []pathSegment) Node {
+	current := root
+	for _, segment := range segments {
+		switch n := current.(type) {
+		case *objectNode:
+			if segment.isIndex {
+				return nil
+			}
+			current = n.values[segment.key]
+		case *arrayNode:
+			if segment.isIndex == false || segment.index >= len(n.children) {
+				return nil
+			}
+			current = n.children[segment.index]
+		default:
+			return nil
+		}
+		if current == nil {
+			return nil
+		}
+	}
+	return current
+}
@@ -0,0 +1,44 @@
+package nodes
+
+// NextWithKey returns the next object member with the same key as the node in document order,
+// or the previous one if backward, wrapping around at the ends. It's nil if the node isn't an
+// object member or no other member has its key.
+func NextWithKey(root Node, node Node, backward bool) Node {
+	if isMember(node) == false {
+		return nil
+	}
+	key := node.abstract().key
+
+	all := appendDescendants([]Node{root}, root)
+	idx := indexOf(all, node)
+	if idx < 0 {
+		return nil
+	}
+
+	step := 1
+	if backward {
+		step = -1
+	}
+	for count := 1; count < len(all); count++ {
+		candidate := all[((idx+step*count)%len(all)+len(all))%len(all)]
+		if isMember(candidate) && candidate.abstract().key == key {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// IsDescendant checks if the node is below the ancestor.
+func IsDescendant(node Node, ancestor Node) bool {
+	for parent := node.Parent(); parent != nil; parent = parent.Parent() {
+		if parent == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+func isMember(node Node) bool {
+	_, isObject := node.Parent().(*objectNode)
+	return isObject
+}
@@ -1,6 +1,7 @@
 package nodes
 
 import (
+	"github.com/benweidig/trex/input"
 	"github.com/rivo/tview"
 )
 
@@ -13,9 +14,18 @@ type Node interface {
 	// Path is the JSONPath of the node (http://goessner.net/articles/JsonPath/).
 	Path() string
 
+	// Parent is the node containing this node, nil for the root.
+	Parent() Node
+
 	// Children contains all children of the node, might be empty.
 	Children() []Node
 
+	// Position is the location of the node in the source document, might be unknown.
+	Position() input.Position
+
+	// Change is the difference to another document, only set in diff trees.
+	Change() Change
+
 	// Format writes the formatted node (and its children) into a formatter.
 	Format(f Formatter, indentLvl int)
 
@@ -27,6 +37,17 @@ type Node interface {
 
 	// ToggleExpansion tries to toggle the current state of expansion/collapse.
 	ToggleExpansion()
+
+	// ToggleDecoding tries to show/hide the decoded value (base64, JWT, URL-encoding) as a child.
+	ToggleDecoding()
+
+	// abstract gives access to the shared fields of all node types.
+	abstract() *abstractNode
+}
+
+// KeyOf returns the key of an object member, it's empty for all other nodes.
+func KeyOf(node Node) string {
+	return node.abstract().key
 }
 
 // abstractNode is helper struct so we don't need to implement all the methods of Node
@@ -39,13 +60,17 @@ type abstractNode struct {
 	parent     Node
 	children   []Node
 	collapsed  bool
+	position   input.Position
+	change     Change
+	changeInfo string
+	sizeStats  *SizeStats
 }
 
 // Label contains additional info for nicer output.
 func (n abstractNode) Label() Label {
-	return Label{
+	return n.changeLabel(Label{
 		text: tview.Escape(n.identifier),
-	}
+	})
 }
 
 // Path is the JSONPath of the node (http://goessner.net/articles/JsonPath/).
@@ -53,11 +78,26 @@ func (n abstractNode) Path() string {
 	return n.path
 }
 
+// Parent is the node containing this node, nil for the root.
+func (n abstractNode) Parent() Node {
+	return n.parent
+}
+
 // Children contains all children of the node, might be empty.
 func (n *abstractNode) Children() []Node {
 	return n.children
 }
 
+// Position is the location of the node in the source document, might be unknown.
+func (n abstractNode) Position() input.Position {
+	return n.position
+}
+
+// Change is the difference to another document, only set in diff trees.
+func (n abstractNode) Change() Change {
+	return n.change
+}
+
 // IsCollapsable determinates if a node can collapse its children.
 func (n abstractNode) IsCollapsable() bool {
 	return len(n.children) > 0
@@ -72,3 +112,19 @@ func (n abstractNode) IsCollapsed() bool {
 func (n *abstractNode) ToggleExpansion() {
 	return
 }
+
+// ToggleDecoding tries to show/hide the decoded value (base64, JWT, URL-encoding) as a child.
+func (n *abstractNode) ToggleDecoding() {
+	return
+}
+
+func (n *abstractNode) abstract() *abstractNode {
+	return n
+}
+
+// changeLabel adds the change of the node to the label.
+func (n abstractNode) changeLabel(l Label) Label {
+	l.change = n.change
+	l.changeInfo = n.changeInfo
+	return l
+}
@@ -15,7 +15,7 @@ type objectNode struct {
 
 func (n *objectNode) Label() Label {
 	if len(n.label.text) > 0 || len(n.label.additionalInfo) > 0 {
-		return n.label
+		return n.changeLabel(n.label)
 	}
 	if n.parent == nil {
 		n.label.text = "<root>"
@@ -28,7 +28,7 @@ func (n *objectNode) Label() Label {
 	}
 	n.label.additionalInfo = fmt.Sprintf("{%d}", len(n.values))
 
-	return n.label
+	return n.changeLabel(n.label)
 }
 
 func (n *objectNode) Format(f Formatter, indentLvl int) {
@@ -0,0 +1,302 @@
+package nodes
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PathSyntax is an enum/constant helper type for the different path notations
+type PathSyntax string
+
+const (
+	// PathSyntaxJSONPath represents JSONPath (http://goessner.net/articles/JsonPath/)
+	PathSyntaxJSONPath PathSyntax = "JSONPath"
+
+	// PathSyntaxJSONPointer represents JSON Pointer (RFC 6901)
+	PathSyntaxJSONPointer PathSyntax = "JSON Pointer"
+
+	// PathSyntaxJQ represents jq filters
+	PathSyntaxJQ PathSyntax = "jq"
+
+	// PathSyntaxYQ represents yq expressions
+	PathSyntaxYQ PathSyntax = "yq"
+
+	// PathSyntaxJavaScript represents JavaScript property accessors
+	PathSyntaxJavaScript PathSyntax = "JavaScript"
+
+	// PathSyntaxPython represents Python subscriptions
+	PathSyntaxPython PathSyntax = "Python"
+
+	// PathSyntaxGoTemplate represents Go text/template actions
+	PathSyntaxGoTemplate PathSyntax = "Go template"
+)
+
+// PathSyntaxes contains all available path syntaxes in display order.
+var PathSyntaxes = []PathSyntax{
+	PathSyntaxJSONPath,
+	PathSyntaxJSONPointer,
+	PathSyntaxJQ,
+	PathSyntaxYQ,
+	PathSyntaxJavaScript,
+	PathSyntaxPython,
+	PathSyntaxGoTemplate,
+}
+
+// identifierRegex matches keys that are safe to use with dot-notation.
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// javaScriptIdentifierRegex is like identifierRegex, but JavaScript also allows dollar signs.
+var javaScriptIdentifierRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// rootVariable is used as the name of the document in programming languages.
+const rootVariable = "data"
+
+// pathSegment is a single step from a node to one of its children.
+type pathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// BuildPath builds the path of the node in the requested syntax, unknown syntaxes fall back to JSONPath.
+// Decoded values only have their marked JSONPath, like $.token<jwt>.header, in all syntaxes,
+// see isDecoded.
+func BuildPath(node Node, syntax PathSyntax) string {
+	if syntax == PathSyntaxJSONPath || isDecoded(node) {
+		return node.Path()
+	}
+
+	segments := pathSegments(node)
+
+	switch syntax {
+	case PathSyntaxJSONPointer:
+		return buildJSONPointer(segments)
+
+	case PathSyntaxJQ:
+		return buildJQPath(segments, false)
+
+	case PathSyntaxYQ:
+		return buildJQPath(segments, true)
+
+	case PathSyntaxJavaScript:
+		return buildJavaScriptPath(segments)
+
+	case PathSyntaxPython:
+		return buildPythonPath(segments)
+
+	case PathSyntaxGoTemplate:
+		return buildGoTemplatePath(segments)
+
+	default:
+		return node.Path()
+	}
+}
+
+// FindByPointer returns the node at the JSON Pointer (RFC 6901), or nil if it doesn't exist.
+// Decoded values can't be found, see isDecoded.
+func FindByPointer(root Node, pointer string) Node {
+	if len(pointer) == 0 {
+		return root
+	}
+	if strings.HasPrefix(pointer, "/") == false {
+		return nil
+	}
+
+	node := root
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.Replace(token, "~1", "/", -1)
+		token = strings.Replace(token, "~0", "~", -1)
+
+		switch n := node.(type) {
+		case *objectNode:
+			node = n.values[token]
+		case *arrayNode:
+			idx, err := strconv.Atoi(token)
+			if err != nil || idx < 0 || idx >= len(n.children) {
+				return nil
+			}
+			node = n.children[idx]
+		default:
+			return nil
+		}
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// pathSegments walks up the parents to collect the segments from the root to the node.
+func pathSegments(node Node) []pathSegment {
+	var segments []pathSegment
+
+	for n := node.abstract(); n.parent != nil; n = n.parent.abstract() {
+		switch parent := n.parent.(type) {
+		case *arrayNode:
+			for idx, sibling := range parent.children {
+				if sibling.abstract() == n {
+					segments = append(segments, pathSegment{index: idx, isIndex: true})
+					break
+				}
+			}
+
+		case *stringNode:
+			// Decoded values don't have a segment of their own, they aren't addressable in the
+			// document, see isDecoded
+			continue
+
+		default:
+			segments = append(segments, pathSegment{key: n.key})
+		}
+	}
+
+	for left, right := 0, len(segments)-1; left < right; left, right = left+1, right-1 {
+		segments[left], segments[right] = segments[right], segments[left]
+	}
+	return segments
+}
+
+// isDecoded reports whether the node is part of a decoded string value.
+// Decoded values only show what a string contains, they aren't part of the document:
+// they can't be edited, addressed by a pointer or query, and aren't counted or searched.
+func isDecoded(node Node) bool {
+	for n := node.Parent(); n != nil; n = n.Parent() {
+		if _, isString := n.(*stringNode); isString {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonPathChild appends a key to a JSONPath, using bracket-notation if needed.
+func jsonPathChild(path string, key string) string {
+	if identifierRegex.MatchString(key) {
+		return path + "." + key
+	}
+	return path + "[" + quoteJSONPathKey(key) + "]"
+}
+
+// jsonPathIndex appends an array index to a JSONPath.
+func jsonPathIndex(path string, idx int) string {
+	return path + "[" + strconv.Itoa(idx) + "]"
+}
+
+// quoteJSONPathKey single-quotes a key, escaping backslashes and single quotes.
+func quoteJSONPathKey(key string) string {
+	escaped := strings.Replace(key, `\`, `\\`, -1)
+	escaped = strings.Replace(escaped, `'`, `\'`, -1)
+	return "'" + escaped + "'"
+}
+
+func buildJSONPointer(segments []pathSegment) string {
+	var pointer string
+	for _, segment := range segments {
+		if segment.isIndex {
+			pointer += "/" + strconv.Itoa(segment.index)
+			continue
+		}
+		token := strings.Replace(segment.key, "~", "~0", -1)
+		token = strings.Replace(token, "/", "~1", -1)
+		pointer += "/" + token
+	}
+	return pointer
+}
+
+// buildJQPath builds a jq path, yq uses the same syntax but prefers brackets for quoted keys.
+func buildJQPath(segments []pathSegment, yq bool) string {
+	var path strings.Builder
+	for _, segment := range segments {
+		switch {
+		case segment.isIndex:
+			if path.Len() == 0 {
+				path.WriteString(".")
+			}
+			path.WriteString("[" + strconv.Itoa(segment.index) + "]")
+
+		case identifierRegex.MatchString(segment.key):
+			path.WriteString("." + segment.key)
+
+		case yq:
+			path.WriteString(".[" + quoteJSONString(segment.key) + "]")
+
+		default:
+			path.WriteString("." + quoteJSONString(segment.key))
+		}
+	}
+
+	if path.Len() == 0 {
+		return "."
+	}
+	return path.String()
+}
+
+func buildJavaScriptPath(segments []pathSegment) string {
+	path := rootVariable
+	for _, segment := range segments {
+		switch {
+		case segment.isIndex:
+			path += "[" + strconv.Itoa(segment.index) + "]"
+
+		case javaScriptIdentifierRegex.MatchString(segment.key):
+			path += "." + segment.key
+
+		default:
+			path += "[" + quoteJSONString(segment.key) + "]"
+		}
+	}
+	return path
+}
+
+func buildPythonPath(segments []pathSegment) string {
+	path := rootVariable
+	for _, segment := range segments {
+		if segment.isIndex {
+			path += "[" + strconv.Itoa(segment.index) + "]"
+		} else {
+			path += "[" + quoteJSONString(segment.key) + "]"
+		}
+	}
+	return path
+}
+
+// buildGoTemplatePath uses field chaining as long as possible, and the "index" function
+// for array indices and keys that aren't valid identifiers.
+func buildGoTemplatePath(segments []pathSegment) string {
+	chain := ""
+	var indexArgs []string
+	for _, segment := range segments {
+		switch {
+		case len(indexArgs) == 0 && segment.isIndex == false && identifierRegex.MatchString(segment.key):
+			chain += "." + segment.key
+
+		case segment.isIndex:
+			indexArgs = append(indexArgs, strconv.Itoa(segment.index))
+
+		default:
+			indexArgs = append(indexArgs, strconv.Quote(segment.key))
+		}
+	}
+
+	if len(chain) == 0 {
+		chain = "."
+	}
+	if len(indexArgs) == 0 {
+		return "{{ " + chain + " }}"
+	}
+	return "{{ index " + chain + " " + strings.Join(indexArgs, " ") + " }}"
+}
+
+// quoteJSONString quotes a string with the JSON escaping rules, which are also valid
+// in jq, JavaScript and Python.
+func quoteJSONString(value string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return strconv.Quote(value)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
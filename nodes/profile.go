@@ -0,0 +1,135 @@
+package nodes
+
+import (
+	"errors"
+	"sort"
+)
+
+// ValueCount is a value of a field and how often it was seen.
+type ValueCount struct {
+	Value string
+	Count int
+}
+
+// FieldProfile describes a key path seen in the elements of an array, like jq's group_by would.
+type FieldProfile struct {
+	// Path is relative to the elements, "[]" stands for all elements of nested arrays
+	Path string
+
+	// Present is the count of elements containing the path
+	Present int
+
+	// Types are the observed value types with their counts, the most common first
+	Types []ValueCount
+
+	// Distinct is the count of distinct scalar values
+	Distinct int
+
+	// Top are the most common scalar values, the most common first
+	Top []ValueCount
+
+	// First is the first node seen at the path
+	First Node
+}
+
+// fieldStats collects the values of a path while walking the elements.
+type fieldStats struct {
+	profile FieldProfile
+	types   map[string]int
+	values  map[string]int
+}
+
+// ProfileFields lists every key path of the objects in the array with its presence, types,
+// distinct values and the topN most common values, in order of appearance.
+func ProfileFields(array Node, topN int) ([]FieldProfile, error) {
+	if _, isArray := array.(*arrayNode); isArray == false {
+		return nil, errors.New("Only arrays can be profiled")
+	}
+
+	var order []string
+	stats := make(map[string]*fieldStats)
+
+	for _, element := range array.Children() {
+		seen := make(map[string]bool)
+		var walk func(node Node, path string)
+		walk = func(node Node, path string) {
+			switch n := node.(type) {
+			case *objectNode:
+				for _, member := range n.children {
+					key := member.abstract().key
+					memberPath := buildJQPath([]pathSegment{{key: key}}, false)
+					if len(path) > 0 {
+						memberPath = path + memberPath
+					}
+					field := stats[memberPath]
+					if field == nil {
+						field = &fieldStats{
+							profile: FieldProfile{Path: memberPath, First: member},
+							types:   make(map[string]int),
+							values:  make(map[string]int),
+						}
+						stats[memberPath] = field
+						order = append(order, memberPath)
+					}
+					if seen[memberPath] == false {
+						seen[memberPath] = true
+						field.profile.Present++
+					}
+					field.add(member)
+					walk(member, memberPath)
+				}
+
+			case *arrayNode:
+				for _, child := range n.children {
+					walk(child, path+"[]")
+				}
+			}
+		}
+		walk(element, "")
+	}
+
+	profiles := make([]FieldProfile, len(order))
+	for idx, path := range order {
+		field := stats[path]
+		field.profile.Types = mostCommon(field.types, 0)
+		field.profile.Distinct = len(field.values)
+		field.profile.Top = mostCommon(field.values, topN)
+		profiles[idx] = field.profile
+	}
+	return profiles, nil
+}
+
+func (f *fieldStats) add(node Node) {
+	switch n := node.(type) {
+	case *objectNode:
+		f.types[string(ValueTypeObject)]++
+	case *arrayNode:
+		f.types[string(ValueTypeArray)]++
+	case *stringNode:
+		// Quoted, so strings can't be mistaken for other values, like "1" for 1
+		f.types[string(ValueTypeString)]++
+		f.values[quoteJSONString(n.value)]++
+	default:
+		_, valueType, _ := EditableValue(node)
+		f.types[string(valueType)]++
+		f.values[CellText(node)]++
+	}
+}
+
+// mostCommon sorts the counts descending, ties by value, limited to topN if it's positive.
+func mostCommon(counts map[string]int, topN int) []ValueCount {
+	sorted := make([]ValueCount, 0, len(counts))
+	for value, count := range counts {
+		sorted = append(sorted, ValueCount{Value: value, Count: count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Value < sorted[j].Value
+	})
+	if topN > 0 && len(sorted) > topN {
+		sorted = sorted[:topN]
+	}
+	return sorted
+}
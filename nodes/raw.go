@@ -0,0 +1,24 @@
+package nodes
+
+// ToRaw converts a node back into the plain values it was built from, so it can be
+// processed by other tools (like jq). Decoded children of strings are not included.
+func ToRaw(node Node) interface{} {
+	switch n := node.(type) {
+	case *objectNode:
+		raw := make(map[string]interface{}, len(n.values))
+		for key, value := range n.values {
+			raw[key] = ToRaw(value)
+		}
+		return raw
+
+	case *arrayNode:
+		raw := make([]interface{}, len(n.children))
+		for idx, child := range n.children {
+			raw[idx] = ToRaw(child)
+		}
+		return raw
+
+	default:
+		return scalarValue(node)
+	}
+}
@@ -0,0 +1,133 @@
+package nodes
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SearchMode defines how a search pattern is interpreted.
+type SearchMode string
+
+const (
+	// SearchModePlain matches the pattern literally
+	SearchModePlain SearchMode = "plain"
+
+	// SearchModeIgnoreCase matches the pattern literally, but case-insensitive
+	SearchModeIgnoreCase SearchMode = "ignore case"
+
+	// SearchModeRegex interprets the pattern as a regular expression
+	SearchModeRegex SearchMode = "regex"
+)
+
+// SearchModes contains all search modes in the order they are cycled through.
+var SearchModes = []SearchMode{
+	SearchModePlain,
+	SearchModeIgnoreCase,
+	SearchModeRegex,
+}
+
+// highlightTag only changes the background, so the foreground of the formatters stays intact.
+const (
+	highlightTag      = "[:olive]"
+	highlightResetTag = "[:-]"
+)
+
+// tagRegex matches the color tags and escaped brackets of tview, they must not be highlighted.
+var tagRegex = regexp.MustCompile(`\[([a-zA-Z]+|#[0-9a-zA-Z]{6}|\-)?(:([a-zA-Z]+|#[0-9a-zA-Z]{6}|\-)?(:([lbdru]+|\-)?)?)?\]|\[[a-zA-Z0-9_,;: \-\."#]+\[\]+`)
+
+// Matcher finds the occurrences of a search pattern.
+type Matcher struct {
+	regex *regexp.Regexp
+}
+
+// NewMatcher builds a Matcher for the pattern, only regex patterns might be invalid.
+func NewMatcher(pattern string, mode SearchMode) (*Matcher, error) {
+	var expression string
+	switch mode {
+	case SearchModeIgnoreCase:
+		expression = "(?i)" + regexp.QuoteMeta(pattern)
+	case SearchModeRegex:
+		expression = pattern
+	default:
+		expression = regexp.QuoteMeta(pattern)
+	}
+
+	regex, err := regexp.Compile(expression)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid regex: %s", strings.TrimPrefix(err.Error(), "error parsing regexp: "))
+	}
+	return &Matcher{
+		regex: regex,
+	}, nil
+}
+
+// MatchString reports whether the text contains a match.
+func (m *Matcher) MatchString(text string) bool {
+	return m.regex.MatchString(text)
+}
+
+// Highlight surrounds all matches in the text with color tags, existing tags are left untouched.
+func (m *Matcher) Highlight(text string) string {
+	var builder strings.Builder
+	highlightSegment := func(segment string) {
+		last := 0
+		for _, match := range m.regex.FindAllStringIndex(segment, -1) {
+			if match[0] == match[1] {
+				continue
+			}
+			builder.WriteString(segment[last:match[0]])
+			builder.WriteString(highlightTag)
+			builder.WriteString(segment[match[0]:match[1]])
+			builder.WriteString(highlightResetTag)
+			last = match[1]
+		}
+		builder.WriteString(segment[last:])
+	}
+
+	last := 0
+	for _, tag := range tagRegex.FindAllStringIndex(text, -1) {
+		highlightSegment(text[last:tag[0]])
+		builder.WriteString(text[tag[0]:tag[1]])
+		last = tag[1]
+	}
+	highlightSegment(text[last:])
+
+	return builder.String()
+}
+
+// Search returns all nodes below (and including) the root whose key or scalar value matches,
+// in document order.
+func Search(root Node, m *Matcher) []Node {
+	var matches []Node
+	var visit func(node Node)
+	visit = func(node Node) {
+		if key := node.abstract().key; len(key) > 0 && m.MatchString(key) {
+			matches = append(matches, node)
+		} else if value, ok := scalarText(node); ok && m.MatchString(value) {
+			matches = append(matches, node)
+		}
+		for _, child := range node.Children() {
+			visit(child)
+		}
+	}
+	visit(root)
+	return matches
+}
+
+// scalarText returns the value of scalar nodes like the formatters print them.
+func scalarText(node Node) (string, bool) {
+	switch n := node.(type) {
+	case *stringNode:
+		return n.value, true
+	case *numberNode:
+		return formatNumber(n.value), true
+	case *boolNode:
+		return strconv.FormatBool(n.value), true
+	case *nullNode:
+		return "null", true
+	default:
+		return "", false
+	}
+}
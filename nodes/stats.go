@@ -0,0 +1,131 @@
+package nodes
+
+import (
+	"fmt"
+	"sort"
+)
+
+// SizeStats describes how heavy a node is, including all its descendants.
+type SizeStats struct {
+	// Size is the byte count of the compact JSON serialization
+	Size int
+
+	// Descendants is the count of all nodes below
+	Descendants int
+
+	// Depth is the maximum count of levels below, 0 for leaves
+	Depth int
+
+	// Leaves is the count of scalars and empty objects/arrays, the node itself if it's a leaf
+	Leaves int
+}
+
+func (s SizeStats) String() string {
+	return fmt.Sprintf("%s, %d nodes, depth %d, %d leaves", FormatBytes(s.Size), s.Descendants, s.Depth, s.Leaves)
+}
+
+// Measure returns the size stats of the node, they are cached until the subtree changes.
+// Decoded values are ignored, see isDecoded.
+func Measure(node Node) SizeStats {
+	n := node.abstract()
+	if n.sizeStats != nil {
+		return *n.sizeStats
+	}
+
+	var stats SizeStats
+	switch v := node.(type) {
+	case *objectNode:
+		stats.Size = 2
+		for _, child := range n.children {
+			childStats := Measure(child)
+			stats.Size += len(quoteJSONString(child.abstract().key)) + 1 + childStats.Size
+			stats.add(childStats)
+		}
+		if len(n.children) > 1 {
+			stats.Size += len(n.children) - 1
+		}
+
+	case *arrayNode:
+		stats.Size = 2
+		for _, child := range n.children {
+			childStats := Measure(child)
+			stats.Size += childStats.Size
+			stats.add(childStats)
+		}
+		if len(n.children) > 1 {
+			stats.Size += len(n.children) - 1
+		}
+
+	case *stringNode:
+		stats.Size = len(quoteJSONString(v.value))
+
+	case *numberNode:
+		stats.Size = len(formatNumber(v.value))
+
+	case *boolNode:
+		stats.Size = len(fmt.Sprintf("%t", v.value))
+
+	default:
+		stats.Size = len("null")
+	}
+
+	if stats.Leaves == 0 {
+		stats.Leaves = 1
+	}
+
+	n.sizeStats = &stats
+	return stats
+}
+
+// IsContainer reports whether the node is an object or array, decoded values don't count.
+func IsContainer(node Node) bool {
+	switch node.(type) {
+	case *objectNode, *arrayNode:
+		return true
+	default:
+		return false
+	}
+}
+
+// SortedBySize returns a copy of the children with the largest first.
+func SortedBySize(children []Node) []Node {
+	sorted := make([]Node, len(children))
+	copy(sorted, children)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return Measure(sorted[i]).Size > Measure(sorted[j]).Size
+	})
+	return sorted
+}
+
+// FormatBytes formats a byte count with a binary unit, like "12.3 KB".
+func FormatBytes(size int) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	value := float64(size) / unit
+	units := []string{"KB", "MB", "GB", "TB"}
+	idx := 0
+	for value >= unit && idx < len(units)-1 {
+		value /= unit
+		idx++
+	}
+	return fmt.Sprintf("%.1f %s", value, units[idx])
+}
+
+// add accumulates the stats of a child.
+func (s *SizeStats) add(child SizeStats) {
+	s.Descendants += 1 + child.Descendants
+	s.Leaves += child.Leaves
+	if child.Depth+1 > s.Depth {
+		s.Depth = child.Depth + 1
+	}
+}
+
+// invalidateSizes drops the cached stats of the node and its ancestors after a change.
+func invalidateSizes(node Node) {
+	for ; node != nil; node = node.Parent() {
+		node.abstract().sizeStats = nil
+	}
+}
@@ -8,3 +8,29 @@ type stringNode struct {
 func (n *stringNode) Format(f Formatter, indentLvl int) {
 	f.writeString(n.value, n)
 }
+
+func (n *stringNode) ToggleExpansion() {
+	n.collapsed = !n.collapsed
+}
+
+// ToggleDecoding adds the decoded value as the only child, or removes it if already decoded.
+func (n *stringNode) ToggleDecoding() {
+	if len(n.children) > 0 {
+		n.children = nil
+		return
+	}
+
+	decoder, raw, ok := decode(n.value)
+	if ok == false {
+		return
+	}
+
+	// The decoder marks the path, see isDecoded
+	identifier := "<" + decoder.Name() + ">"
+	decoded, err := buildNodes(n.path+identifier, "", identifier, n, raw)
+	if err != nil {
+		return
+	}
+	n.children = []Node{decoded}
+	n.collapsed = false
+}
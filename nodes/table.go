@@ -0,0 +1,161 @@
+package nodes
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TableColumn is a column of the table view of an array, either a key of the object elements,
+// or the values of the elements that aren't objects, they have no keys.
+type TableColumn struct {
+	Key     string
+	IsValue bool
+}
+
+// EnclosingArray returns the node if it's an array, otherwise the nearest array above it, or nil.
+func EnclosingArray(node Node) Node {
+	for current := node; current != nil; current = current.Parent() {
+		if _, isArray := current.(*arrayNode); isArray {
+			return current
+		}
+	}
+	return nil
+}
+
+// TableColumns returns the union of the keys of all elements of the array, in order of appearance.
+// Elements that aren't objects get the value column, at the front.
+func TableColumns(array Node) []TableColumn {
+	var columns []TableColumn
+	seen := make(map[string]bool)
+	hasValues := false
+	for _, element := range array.Children() {
+		object, isObject := element.(*objectNode)
+		if isObject == false {
+			hasValues = true
+			continue
+		}
+		for _, member := range object.children {
+			key := member.abstract().key
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			columns = append(columns, TableColumn{Key: key})
+		}
+	}
+
+	if hasValues {
+		columns = append([]TableColumn{{IsValue: true}}, columns...)
+	}
+	return columns
+}
+
+// TableCell returns the node of the element in the column, nil if the element doesn't have it.
+func TableCell(element Node, column TableColumn) Node {
+	object, isObject := element.(*objectNode)
+	if isObject == false {
+		if column.IsValue {
+			return element
+		}
+		return nil
+	}
+	if column.IsValue {
+		return nil
+	}
+	return object.values[column.Key]
+}
+
+// CellText is the single-line text of a node, objects and arrays are summarized by their size.
+func CellText(node Node) string {
+	if text, ok := scalarText(node); ok {
+		return safeString(text)
+	}
+	switch node.(type) {
+	case *objectNode:
+		return fmt.Sprintf("{%d}", len(node.Children()))
+	case *arrayNode:
+		return fmt.Sprintf("[%d]", len(node.Children()))
+	}
+	return ""
+}
+
+// CompareValues orders two nodes for sorting, -1, 0 or 1 like strings.Compare.
+// Values of different types are ordered null, booleans, numbers, strings, arrays, objects,
+// missing values (nil) are always last.
+func CompareValues(a Node, b Node) int {
+	if a == nil || b == nil {
+		switch {
+		case a == b:
+			return 0
+		case a == nil:
+			return 1
+		default:
+			return -1
+		}
+	}
+
+	rankA, rankB := valueRank(a), valueRank(b)
+	if rankA != rankB {
+		if rankA < rankB {
+			return -1
+		}
+		return 1
+	}
+
+	switch x := a.(type) {
+	case *boolNode:
+		y := b.(*boolNode)
+		return compareBools(x.value, y.value)
+
+	case *numberNode:
+		y := b.(*numberNode)
+		switch {
+		case x.value < y.value:
+			return -1
+		case x.value > y.value:
+			return 1
+		}
+		return 0
+
+	case *stringNode:
+		return strings.Compare(x.value, b.(*stringNode).value)
+	}
+
+	// Containers are ordered by their size
+	countA, countB := len(a.Children()), len(b.Children())
+	switch {
+	case countA < countB:
+		return -1
+	case countA > countB:
+		return 1
+	}
+	return 0
+}
+
+func valueRank(node Node) int {
+	switch node.(type) {
+	case *nullNode:
+		return 0
+	case *boolNode:
+		return 1
+	case *numberNode:
+		return 2
+	case *stringNode:
+		return 3
+	case *arrayNode:
+		return 4
+	default:
+		return 5
+	}
+}
+
+func compareBools(a bool, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
@@ -3,6 +3,7 @@ package nodes
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/benweidig/trex/input"
@@ -12,10 +13,11 @@ import (
 type Tree struct {
 	fileType input.FileType
 	root     Node
+	history  History
 }
 
-// NewTree builds a new tree
-func NewTree(fileType input.FileType, raw interface{}) (*Tree, error) {
+// NewTree builds a new tree, the positions are optional.
+func NewTree(fileType input.FileType, raw interface{}, positions input.Positions) (*Tree, error) {
 	t := &Tree{
 		fileType: fileType,
 	}
@@ -26,6 +28,10 @@ func NewTree(fileType input.FileType, raw interface{}) (*Tree, error) {
 	}
 	t.root = root
 
+	if len(positions) > 0 {
+		locateNodes(root, "", positions)
+	}
+
 	return t, nil
 }
 
@@ -62,7 +68,7 @@ func buildNodes(path string, key string, identifier string, parent Node, j inter
 				path:       path,
 				parent:     parent,
 			},
-			safeString(value),
+			value,
 		}
 
 	case float64:
@@ -100,7 +106,7 @@ func buildNodes(path string, key string, identifier string, parent Node, j inter
 
 		for idx, childKey := range keys {
 			childInterface := value[childKey]
-			childPath := path + "." + childKey
+			childPath := jsonPathChild(path, childKey)
 			childNode, err := buildNodes(childPath, childKey, childKey, objectNode, childInterface)
 			if err != nil {
 				return nil, err
@@ -134,7 +140,7 @@ func buildNodes(path string, key string, identifier string, parent Node, j inter
 
 		for idx, childKey := range keys {
 			childInterface := value[childKey]
-			childPath := path + "." + childKey
+			childPath := jsonPathChild(path, childKey)
 			childNode, err := buildNodes(childPath, childKey, childKey, objectNode, childInterface)
 			if err != nil {
 				return nil, err
@@ -149,14 +155,14 @@ func buildNodes(path string, key string, identifier string, parent Node, j inter
 			abstractNode{
 				key:        key,
 				identifier: identifier,
-				path:       path + "[*]",
+				path:       path,
 				parent:     parent,
 				children:   make([]Node, len(value)),
 			},
 		}
 		for idx, childInterface := range value {
 			arrayIndex := fmt.Sprintf("[%d]", idx)
-			childNode, err := buildNodes(path+arrayIndex, "", arrayIndex, arrayNode, childInterface)
+			childNode, err := buildNodes(jsonPathIndex(path, idx), "", arrayIndex, arrayNode, childInterface)
 			if err != nil {
 				return nil, err
 			}
@@ -180,6 +186,23 @@ func buildNodes(path string, key string, identifier string, parent Node, j inter
 	return node, nil
 }
 
+// locateNodes assigns the positions by the JSON Pointer of every node.
+func locateNodes(node Node, pointer string, positions input.Positions) {
+	_, isArray := node.(*arrayNode)
+
+	n := node.abstract()
+	n.position = positions[pointer]
+
+	for idx, child := range n.children {
+		token := child.abstract().key
+		if isArray {
+			token = strconv.Itoa(idx)
+		}
+		locateNodes(child, input.ChildPointer(pointer, token), positions)
+	}
+}
+
+// safeString escapes line breaks and tabs, so the string can be displayed on a single line.
 func safeString(str string) string {
 	safe := str
 	safe = strings.Replace(safe, "\n", "\\n", -1)
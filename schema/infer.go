@@ -0,0 +1,208 @@
+package schema
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// Dialect is the $schema of inferred schemas.
+const Dialect = "https://json-schema.org/draft/2020-12/schema"
+
+const (
+	// maxEnumValues is the maximum count of distinct strings that are inferred as enum
+	maxEnumValues = 5
+
+	// minEnumSamples is the minimum count of strings before an enum is inferred, so a
+	// few unique values aren't mistaken for a closed set
+	minEnumSamples = 2 * maxEnumValues
+)
+
+var (
+	emailRegex = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	uuidRegex  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// formats are the detected string formats, the first one matching all strings wins.
+var formats = []struct {
+	name    string
+	matches func(value string) bool
+}{
+	{"date-time", func(value string) bool {
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	}},
+	{"date", func(value string) bool {
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	}},
+	{"email", emailRegex.MatchString},
+	{"uuid", uuidRegex.MatchString},
+	{"ipv4", func(value string) bool {
+		ip := net.ParseIP(value)
+		return ip != nil && ip.To4() != nil
+	}},
+	{"uri", func(value string) bool {
+		u, err := url.Parse(value)
+		return err == nil && len(u.Scheme) > 0 && (len(u.Host) > 0 || len(u.Opaque) > 0)
+	}},
+}
+
+// shape accumulates what all samples at the same location look like.
+type shape struct {
+	count int
+	types map[string]bool
+
+	strings     int
+	values      map[string]bool
+	formatCount map[string]int
+
+	objects    int
+	properties map[string]*shape
+
+	items *shape
+}
+
+func newShape() *shape {
+	return &shape{
+		types:       make(map[string]bool),
+		values:      make(map[string]bool),
+		formatCount: make(map[string]int),
+		properties:  make(map[string]*shape),
+	}
+}
+
+// Infer builds a schema that all samples are valid against. The elements of arrays are
+// merged into a single item schema, keys missing in some objects are optional.
+func Infer(samples ...interface{}) map[string]interface{} {
+	s := newShape()
+	for _, sample := range samples {
+		s.add(Normalize(sample))
+	}
+
+	schema := s.schema()
+	schema["$schema"] = Dialect
+	return schema
+}
+
+func (s *shape) add(value interface{}) {
+	s.count++
+	s.types[typeName(value)] = true
+
+	switch v := value.(type) {
+	case string:
+		s.strings++
+		if len(s.values) <= maxEnumValues {
+			s.values[v] = true
+		}
+		for _, format := range formats {
+			if format.matches(v) {
+				s.formatCount[format.name]++
+			}
+		}
+
+	case map[string]interface{}:
+		s.objects++
+		for key, child := range v {
+			property, ok := s.properties[key]
+			if ok == false {
+				property = newShape()
+				s.properties[key] = property
+			}
+			property.add(child)
+		}
+
+	case []interface{}:
+		if s.items == nil {
+			s.items = newShape()
+		}
+		for _, item := range v {
+			s.items.add(item)
+		}
+	}
+}
+
+func (s *shape) schema() map[string]interface{} {
+	schema := make(map[string]interface{})
+
+	// Integers are numbers too, so mixed samples are just numbers
+	if s.types["integer"] && s.types["number"] {
+		delete(s.types, "integer")
+	}
+	var types []string
+	for name := range s.types {
+		types = append(types, name)
+	}
+	sort.Strings(types)
+
+	switch len(types) {
+	case 0:
+		return schema
+	case 1:
+		schema["type"] = types[0]
+	default:
+		list := make([]interface{}, len(types))
+		for idx, name := range types {
+			list[idx] = name
+		}
+		schema["type"] = list
+	}
+
+	if s.strings > 0 {
+		s.inferString(schema, len(types) == 1)
+	}
+
+	if s.objects > 0 {
+		properties := make(map[string]interface{}, len(s.properties))
+		var required []string
+		for key, property := range s.properties {
+			properties[key] = property.schema()
+			if property.count == s.objects {
+				required = append(required, key)
+			}
+		}
+		schema["properties"] = properties
+
+		if len(required) > 0 {
+			sort.Strings(required)
+			list := make([]interface{}, len(required))
+			for idx, key := range required {
+				list[idx] = key
+			}
+			schema["required"] = list
+		}
+	}
+
+	if s.items != nil && s.items.count > 0 {
+		schema["items"] = s.items.schema()
+	}
+	return schema
+}
+
+// inferString adds a format if all strings have one, or an enum for few repeated values.
+// Enums are only possible if all samples are strings, other types would be rejected.
+func (s *shape) inferString(schema map[string]interface{}, onlyStrings bool) {
+	for _, format := range formats {
+		if s.formatCount[format.name] == s.strings {
+			schema["format"] = format.name
+			return
+		}
+	}
+
+	if onlyStrings == false || len(s.values) > maxEnumValues || s.strings < minEnumSamples {
+		return
+	}
+	values := make([]string, 0, len(s.values))
+	for value := range s.values {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	enum := make([]interface{}, len(values))
+	for idx, value := range values {
+		enum[idx] = value
+	}
+	schema["enum"] = enum
+}
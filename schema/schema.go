@@ -0,0 +1,167 @@
+// Package schema validates documents against JSON Schemas (draft-07 and 2020-12).
+//
+// The schema and the instance are plain values as produced by encoding/json, YAML maps
+// with interface{} keys are converted. Only references within the schema are supported.
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Violation is a failed keyword at a location of the instance.
+type Violation struct {
+	// Pointer is the JSON Pointer (RFC 6901) of the invalid value
+	Pointer string
+
+	// Keyword is the failed keyword, like "required"
+	Keyword string
+
+	// Message describes the violation
+	Message string
+}
+
+func (v Violation) String() string {
+	pointer := v.Pointer
+	if len(pointer) == 0 {
+		pointer = "/"
+	}
+	return fmt.Sprintf("%s: %s", pointer, v.Message)
+}
+
+// Result contains all violations and the descriptions of the validated values.
+type Result struct {
+	Violations []Violation
+
+	// Descriptions maps the JSON Pointer of a value to the description of its schema
+	Descriptions map[string]string
+}
+
+// IsValid reports whether the instance has no violations.
+func (r *Result) IsValid() bool {
+	return len(r.Violations) == 0
+}
+
+// Schema is a compiled JSON Schema.
+type Schema struct {
+	root interface{}
+
+	// legacy is true for draft-07 and earlier, $ref ignores all sibling keywords there
+	legacy bool
+
+	// anchors contains the "#name" anchors, ids the subschemas with an $id
+	anchors map[string]interface{}
+	ids     map[string]interface{}
+
+	patterns map[string]*regexp.Regexp
+}
+
+// Compile prepares a schema for validation.
+func Compile(raw interface{}) (*Schema, error) {
+	root := Normalize(raw)
+	switch root.(type) {
+	case bool, map[string]interface{}:
+	default:
+		return nil, fmt.Errorf("A schema must be an object or a boolean, not %s", typeName(root))
+	}
+
+	s := &Schema{
+		root:     root,
+		anchors:  make(map[string]interface{}),
+		ids:      make(map[string]interface{}),
+		patterns: make(map[string]*regexp.Regexp),
+	}
+	if object, ok := root.(map[string]interface{}); ok {
+		if dialect, ok := object["$schema"].(string); ok {
+			s.legacy = strings.Contains(dialect, "draft-04") || strings.Contains(dialect, "draft-06") || strings.Contains(dialect, "draft-07")
+		}
+	}
+	s.collectIdentifiers(root)
+
+	return s, nil
+}
+
+// Validate validates the instance against the schema.
+func (s *Schema) Validate(instance interface{}) *Result {
+	v := &validator{schema: s}
+	e := v.eval(s.root, Normalize(instance), "")
+	return &Result{
+		Violations:   e.violations,
+		Descriptions: e.descriptions,
+	}
+}
+
+// collectIdentifiers finds all $anchor and $id keywords, so references can be resolved.
+func (s *Schema) collectIdentifiers(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if anchor, ok := v["$anchor"].(string); ok {
+			s.anchors["#"+anchor] = v
+		}
+		if id, ok := v["$id"].(string); ok {
+			if strings.HasPrefix(id, "#") {
+				// Draft-07 uses plain-name fragments in $id as anchors
+				s.anchors[id] = v
+			} else {
+				s.ids[strings.TrimSuffix(id, "#")] = v
+			}
+		}
+		for _, child := range v {
+			s.collectIdentifiers(child)
+		}
+
+	case []interface{}:
+		for _, child := range v {
+			s.collectIdentifiers(child)
+		}
+	}
+}
+
+// resolve finds the subschema of a reference.
+func (s *Schema) resolve(ref string) (interface{}, error) {
+	base, fragment := ref, ""
+	if idx := strings.Index(ref, "#"); idx >= 0 {
+		base, fragment = ref[:idx], ref[idx:]
+	}
+
+	document := s.root
+	if len(base) > 0 {
+		var ok bool
+		if document, ok = s.ids[base]; ok == false {
+			return nil, fmt.Errorf("Can't resolve $ref '%s', only references within the schema are supported", ref)
+		}
+	}
+
+	switch {
+	case len(fragment) <= 1:
+		return document, nil
+
+	case strings.HasPrefix(fragment, "#/"):
+		target, ok := lookupPointer(document, fragment[1:])
+		if ok == false {
+			return nil, fmt.Errorf("Can't resolve $ref '%s'", ref)
+		}
+		return target, nil
+
+	default:
+		target, ok := s.anchors[fragment]
+		if ok == false {
+			return nil, fmt.Errorf("Can't resolve $ref '%s'", ref)
+		}
+		return target, nil
+	}
+}
+
+// pattern compiles and caches a regular expression of the schema.
+func (s *Schema) pattern(expr string) (*regexp.Regexp, error) {
+	if re, ok := s.patterns[expr]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid pattern '%s'", expr)
+	}
+	s.patterns[expr] = re
+	return re, nil
+}
@@ -0,0 +1,434 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/benweidig/trex/input"
+)
+
+// maxDepth limits nested references, so recursive schemas can't loop forever.
+const maxDepth = 200
+
+// evaluation is the result of a schema at one location of the instance.
+type evaluation struct {
+	violations   []Violation
+	descriptions map[string]string
+
+	// props and items are the evaluated properties/items of the location, for the unevaluated* keywords
+	props    map[string]bool
+	items    map[int]bool
+	allItems bool
+}
+
+func newEvaluation() *evaluation {
+	return &evaluation{
+		descriptions: make(map[string]string),
+		props:        make(map[string]bool),
+		items:        make(map[int]bool),
+	}
+}
+
+func (e *evaluation) valid() bool {
+	return len(e.violations) == 0
+}
+
+func (e *evaluation) fail(pointer string, keyword string, format string, args ...interface{}) {
+	e.violations = append(e.violations, Violation{
+		Pointer: pointer,
+		Keyword: keyword,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// describe sets the description of a location, the outermost schema wins.
+func (e *evaluation) describe(pointer string, description string) {
+	if _, exists := e.descriptions[pointer]; exists == false {
+		e.descriptions[pointer] = description
+	}
+}
+
+// add takes the violations and descriptions of a child location.
+func (e *evaluation) add(child *evaluation) {
+	e.violations = append(e.violations, child.violations...)
+	for pointer, description := range child.descriptions {
+		e.describe(pointer, description)
+	}
+}
+
+// include takes everything of another schema applied to the same location.
+func (e *evaluation) include(other *evaluation) {
+	e.add(other)
+	for prop := range other.props {
+		e.props[prop] = true
+	}
+	for idx := range other.items {
+		e.items[idx] = true
+	}
+	e.allItems = e.allItems || other.allItems
+}
+
+type validator struct {
+	schema *Schema
+	depth  int
+}
+
+func (v *validator) eval(schema interface{}, instance interface{}, pointer string) *evaluation {
+	e := newEvaluation()
+
+	sch, ok := schema.(map[string]interface{})
+	if ok == false {
+		if allowed, isBool := schema.(bool); isBool && allowed == false {
+			e.fail(pointer, "false", "No value is allowed here")
+		}
+		return e
+	}
+
+	if description, ok := sch["description"].(string); ok {
+		e.describe(pointer, description)
+	}
+
+	for _, keyword := range []string{"$ref", "$dynamicRef"} {
+		ref, ok := sch[keyword].(string)
+		if ok == false {
+			continue
+		}
+		if v.depth >= maxDepth {
+			e.fail(pointer, keyword, "Too many nested references")
+			return e
+		}
+		target, err := v.schema.resolve(ref)
+		if err != nil {
+			e.fail(pointer, keyword, "%s", err.Error())
+			continue
+		}
+		v.depth++
+		e.include(v.eval(target, instance, pointer))
+		v.depth--
+
+		if v.schema.legacy {
+			return e
+		}
+	}
+
+	v.evalGeneric(sch, instance, pointer, e)
+	v.evalCombinators(sch, instance, pointer, e)
+
+	switch value := instance.(type) {
+	case float64:
+		v.evalNumber(sch, value, pointer, e)
+	case string:
+		v.evalString(sch, value, pointer, e)
+	case []interface{}:
+		v.evalArray(sch, value, pointer, e)
+	case map[string]interface{}:
+		v.evalObject(sch, value, pointer, e)
+	}
+	return e
+}
+
+func (v *validator) evalGeneric(sch map[string]interface{}, instance interface{}, pointer string, e *evaluation) {
+	switch types := sch["type"].(type) {
+	case string:
+		if hasType(instance, types) == false {
+			e.fail(pointer, "type", "Expected %s, got %s", types, typeName(instance))
+		}
+
+	case []interface{}:
+		var names []string
+		matched := false
+		for _, t := range types {
+			name, _ := t.(string)
+			names = append(names, name)
+			matched = matched || hasType(instance, name)
+		}
+		if matched == false {
+			e.fail(pointer, "type", "Expected %s, got %s", strings.Join(names, " or "), typeName(instance))
+		}
+	}
+
+	if enum, ok := sch["enum"].([]interface{}); ok {
+		matched := false
+		for _, allowed := range enum {
+			matched = matched || equal(instance, allowed)
+		}
+		if matched == false {
+			e.fail(pointer, "enum", "Must be one of the %d allowed values", len(enum))
+		}
+	}
+
+	if constant, ok := sch["const"]; ok && equal(instance, constant) == false {
+		e.fail(pointer, "const", "Must be the constant value")
+	}
+}
+
+func (v *validator) evalCombinators(sch map[string]interface{}, instance interface{}, pointer string, e *evaluation) {
+	if schemas, ok := sch["allOf"].([]interface{}); ok {
+		for _, sub := range schemas {
+			e.include(v.eval(sub, instance, pointer))
+		}
+	}
+
+	if schemas, ok := sch["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, sub := range schemas {
+			if result := v.eval(sub, instance, pointer); result.valid() {
+				e.include(result)
+				matched = true
+			}
+		}
+		if matched == false {
+			e.fail(pointer, "anyOf", "Must match at least one of %d schemas", len(schemas))
+		}
+	}
+
+	if schemas, ok := sch["oneOf"].([]interface{}); ok {
+		var matches []*evaluation
+		for _, sub := range schemas {
+			if result := v.eval(sub, instance, pointer); result.valid() {
+				matches = append(matches, result)
+			}
+		}
+		if len(matches) == 1 {
+			e.include(matches[0])
+		} else {
+			e.fail(pointer, "oneOf", "Must match exactly one of %d schemas, matched %d", len(schemas), len(matches))
+		}
+	}
+
+	if sub, ok := sch["not"]; ok && v.eval(sub, instance, pointer).valid() {
+		e.fail(pointer, "not", "Must not match the schema of not")
+	}
+
+	if condition, ok := sch["if"]; ok {
+		result := v.eval(condition, instance, pointer)
+		if result.valid() {
+			e.include(result)
+			if then, ok := sch["then"]; ok {
+				e.include(v.eval(then, instance, pointer))
+			}
+		} else if otherwise, ok := sch["else"]; ok {
+			e.include(v.eval(otherwise, instance, pointer))
+		}
+	}
+}
+
+func (v *validator) evalNumber(sch map[string]interface{}, value float64, pointer string, e *evaluation) {
+	if divisor, ok := number(sch["multipleOf"]); ok && divisor > 0 {
+		quotient := value / divisor
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			e.fail(pointer, "multipleOf", "Must be a multiple of %g", divisor)
+		}
+	}
+	if limit, ok := number(sch["maximum"]); ok && value > limit {
+		e.fail(pointer, "maximum", "Must be <= %g", limit)
+	}
+	if limit, ok := number(sch["exclusiveMaximum"]); ok && value >= limit {
+		e.fail(pointer, "exclusiveMaximum", "Must be < %g", limit)
+	}
+	if limit, ok := number(sch["minimum"]); ok && value < limit {
+		e.fail(pointer, "minimum", "Must be >= %g", limit)
+	}
+	if limit, ok := number(sch["exclusiveMinimum"]); ok && value <= limit {
+		e.fail(pointer, "exclusiveMinimum", "Must be > %g", limit)
+	}
+}
+
+func (v *validator) evalString(sch map[string]interface{}, value string, pointer string, e *evaluation) {
+	length := utf8.RuneCountInString(value)
+	if limit, ok := number(sch["maxLength"]); ok && float64(length) > limit {
+		e.fail(pointer, "maxLength", "Must not be longer than %g characters", limit)
+	}
+	if limit, ok := number(sch["minLength"]); ok && float64(length) < limit {
+		e.fail(pointer, "minLength", "Must not be shorter than %g characters", limit)
+	}
+	if expr, ok := sch["pattern"].(string); ok {
+		re, err := v.schema.pattern(expr)
+		if err != nil {
+			e.fail(pointer, "pattern", "%s", err.Error())
+		} else if re.MatchString(value) == false {
+			e.fail(pointer, "pattern", "Must match the pattern '%s'", expr)
+		}
+	}
+}
+
+func (v *validator) evalArray(sch map[string]interface{}, value []interface{}, pointer string, e *evaluation) {
+	if limit, ok := number(sch["maxItems"]); ok && float64(len(value)) > limit {
+		e.fail(pointer, "maxItems", "Must not have more than %g items", limit)
+	}
+	if limit, ok := number(sch["minItems"]); ok && float64(len(value)) < limit {
+		e.fail(pointer, "minItems", "Must not have less than %g items", limit)
+	}
+	if unique, ok := sch["uniqueItems"].(bool); ok && unique {
+	duplicates:
+		for i := range value {
+			for j := i + 1; j < len(value); j++ {
+				if equal(value[i], value[j]) {
+					e.fail(pointer, "uniqueItems", "Items %d and %d are equal", i, j)
+					break duplicates
+				}
+			}
+		}
+	}
+
+	itemPointer := func(idx int) string {
+		return input.ChildPointer(pointer, fmt.Sprintf("%d", idx))
+	}
+
+	// The tuple form is "prefixItems" in 2020-12, and an array in "items" before
+	prefix, _ := sch["prefixItems"].([]interface{})
+	rest, hasRest := sch["items"]
+	if tuple, isTuple := rest.([]interface{}); isTuple {
+		prefix = tuple
+		rest, hasRest = sch["additionalItems"]
+	}
+
+	for idx, sub := range prefix {
+		if idx >= len(value) {
+			break
+		}
+		e.add(v.eval(sub, value[idx], itemPointer(idx)))
+		e.items[idx] = true
+	}
+	if hasRest {
+		for idx := len(prefix); idx < len(value); idx++ {
+			e.add(v.eval(rest, value[idx], itemPointer(idx)))
+		}
+		e.allItems = true
+	}
+
+	if contains, ok := sch["contains"]; ok {
+		matched := 0
+		for idx, item := range value {
+			if v.eval(contains, item, itemPointer(idx)).valid() {
+				e.items[idx] = true
+				matched++
+			}
+		}
+
+		minContains := 1.0
+		if limit, ok := number(sch["minContains"]); ok {
+			minContains = limit
+		}
+		if float64(matched) < minContains {
+			e.fail(pointer, "contains", "Must contain at least %g matching items, found %d", minContains, matched)
+		}
+		if limit, ok := number(sch["maxContains"]); ok && float64(matched) > limit {
+			e.fail(pointer, "maxContains", "Must not contain more than %g matching items, found %d", limit, matched)
+		}
+	}
+
+	if unevaluated, ok := sch["unevaluatedItems"]; ok && e.allItems == false {
+		for idx, item := range value {
+			if e.items[idx] {
+				continue
+			}
+			e.add(v.eval(unevaluated, item, itemPointer(idx)))
+		}
+		e.allItems = true
+	}
+}
+
+func (v *validator) evalObject(sch map[string]interface{}, value map[string]interface{}, pointer string, e *evaluation) {
+	if limit, ok := number(sch["maxProperties"]); ok && float64(len(value)) > limit {
+		e.fail(pointer, "maxProperties", "Must not have more than %g properties", limit)
+	}
+	if limit, ok := number(sch["minProperties"]); ok && float64(len(value)) < limit {
+		e.fail(pointer, "minProperties", "Must not have less than %g properties", limit)
+	}
+	if required, ok := sch["required"].([]interface{}); ok {
+		v.evalRequired(required, value, pointer, "required", e)
+	}
+
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	properties, _ := sch["properties"].(map[string]interface{})
+	patterns, _ := sch["patternProperties"].(map[string]interface{})
+	additional, hasAdditional := sch["additionalProperties"]
+	names, hasNames := sch["propertyNames"]
+
+	for _, key := range keys {
+		child := value[key]
+		childPointer := input.ChildPointer(pointer, key)
+		matched := false
+
+		if sub, ok := properties[key]; ok {
+			e.add(v.eval(sub, child, childPointer))
+			matched = true
+		}
+		for expr, sub := range patterns {
+			re, err := v.schema.pattern(expr)
+			if err != nil {
+				e.fail(pointer, "patternProperties", "%s", err.Error())
+				continue
+			}
+			if re.MatchString(key) {
+				e.add(v.eval(sub, child, childPointer))
+				matched = true
+			}
+		}
+		if matched == false && hasAdditional {
+			if allowed, isBool := additional.(bool); isBool && allowed == false {
+				e.fail(childPointer, "additionalProperties", "Property '%s' is not allowed", key)
+			} else {
+				e.add(v.eval(additional, child, childPointer))
+			}
+			matched = true
+		}
+		if matched {
+			e.props[key] = true
+		}
+
+		if hasNames {
+			if result := v.eval(names, key, childPointer); result.valid() == false {
+				e.fail(childPointer, "propertyNames", "Property name '%s' is invalid", key)
+			}
+		}
+	}
+
+	// "dependencies" was split into dependentRequired and dependentSchemas in 2019-09
+	for _, keyword := range []string{"dependentRequired", "dependentSchemas", "dependencies"} {
+		dependencies, _ := sch[keyword].(map[string]interface{})
+		for key, dependency := range dependencies {
+			if _, present := value[key]; present == false {
+				continue
+			}
+			if required, isList := dependency.([]interface{}); isList {
+				v.evalRequired(required, value, pointer, keyword, e)
+			} else {
+				e.include(v.eval(dependency, value, pointer))
+			}
+		}
+	}
+
+	if unevaluated, ok := sch["unevaluatedProperties"]; ok {
+		for _, key := range keys {
+			if e.props[key] {
+				continue
+			}
+			childPointer := input.ChildPointer(pointer, key)
+			if allowed, isBool := unevaluated.(bool); isBool && allowed == false {
+				e.fail(childPointer, "unevaluatedProperties", "Property '%s' is not allowed", key)
+			} else {
+				e.add(v.eval(unevaluated, value[key], childPointer))
+			}
+			e.props[key] = true
+		}
+	}
+}
+
+func (v *validator) evalRequired(required []interface{}, value map[string]interface{}, pointer string, keyword string, e *evaluation) {
+	for _, name := range required {
+		key, _ := name.(string)
+		if _, present := value[key]; present == false {
+			e.fail(pointer, keyword, "Missing required property '%s'", key)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package schema
+
+import (
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Normalize converts YAML maps with interface{} keys and integers into plain JSON values.
+func Normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		normalized := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			normalized[toString(key)] = Normalize(child)
+		}
+		return normalized
+
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			normalized[key] = Normalize(child)
+		}
+		return normalized
+
+	case []interface{}:
+		normalized := make([]interface{}, len(v))
+		for idx, child := range v {
+			normalized[idx] = Normalize(child)
+		}
+		return normalized
+
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+
+	default:
+		return value
+	}
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
+
+// typeName returns the JSON Schema type of the value, numbers without fraction are integers.
+func typeName(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) && math.IsInf(v, 0) == false {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return "unknown"
+	}
+}
+
+// hasType checks the value against a type of the "type" keyword.
+func hasType(value interface{}, name string) bool {
+	actual := typeName(value)
+	return actual == name || (name == "number" && actual == "integer")
+}
+
+func equal(a interface{}, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// number returns the numeric value of a keyword.
+func number(value interface{}) (float64, bool) {
+	n, ok := value.(float64)
+	return n, ok
+}
+
+// lookupPointer walks a JSON Pointer (RFC 6901) through the value, fragments are URL-encoded.
+func lookupPointer(value interface{}, pointer string) (interface{}, bool) {
+	if len(pointer) == 0 {
+		return value, true
+	}
+	for _, token := range strings.Split(pointer[1:], "/") {
+		if unescaped, err := url.PathUnescape(token); err == nil {
+			token = unescaped
+		}
+		token = strings.Replace(token, "~1", "/", -1)
+		token = strings.Replace(token, "~0", "~", -1)
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			child, ok := v[token]
+			if ok == false {
+				return nil, false
+			}
+			value = child
+
+		case []interface{}:
+			idx, err := strconv.Atoi(token)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil, false
+			}
+			value = v[idx]
+
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
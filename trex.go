@@ -1,7 +1,13 @@
 package main
 
-import "github.com/benweidig/trex/cmd"
+import (
+	"os"
+
+	"github.com/benweidig/trex/cmd"
+)
 
 func main() {
-	cmd.RootCmd.Execute()
+	if err := cmd.RootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,92 @@
+package widgets
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/benweidig/trex/nodes"
+	"github.com/rivo/tview"
+)
+
+const (
+	aggregateBins     = 10
+	aggregateBarWidth = 30
+)
+
+// AggregateView shows the statistics of the numbers of an array, with a sparkline of the values
+// in document order and a histogram of their distribution.
+type AggregateView struct {
+	*tview.TextView
+
+	monochrome bool
+}
+
+// NewAggregateView builds a new empty AggregateView.
+func NewAggregateView(monochrome bool) *AggregateView {
+	v := &AggregateView{
+		TextView:   tview.NewTextView(),
+		monochrome: monochrome,
+	}
+	v.SetDynamicColors(true)
+	v.SetBorder(true)
+	v.SetBorderPadding(1, 1, 1, 1)
+	v.SetRect(0, 0, 64, 32)
+	return v
+}
+
+// Open displays the aggregate of the array, the sub-path is empty for arrays of numbers.
+func (v *AggregateView) Open(array nodes.Node, subPath string, aggregate *nodes.Aggregate) *AggregateView {
+	title := array.Path()
+	if len(subPath) > 0 {
+		title += " " + subPath
+	}
+	v.SetTitle(" " + title + " ")
+
+	var text strings.Builder
+	row := func(name string, value string) {
+		fmt.Fprintf(&text, "%-8s %s\n", name, value)
+	}
+
+	count := fmt.Sprintf("%d", aggregate.Count())
+	if aggregate.Skipped > 0 {
+		count += fmt.Sprintf(" (%d without a finite number)", aggregate.Skipped)
+	}
+	row("Count", count)
+	row("Sum", nodes.FormatNumber(aggregate.Sum))
+	row("Min", nodes.FormatNumber(aggregate.Min))
+	row("Max", nodes.FormatNumber(aggregate.Max))
+	row("Mean", nodes.FormatNumber(aggregate.Mean))
+	row("Median", nodes.FormatNumber(aggregate.Median))
+	for _, p := range []float64{25, 75, 90, 95, 99} {
+		row(fmt.Sprintf("p%g", p), nodes.FormatNumber(aggregate.Percentile(p)))
+	}
+
+	text.WriteString("\nValues\n")
+	text.WriteString(v.colored(aggregate.Sparkline(60)) + "\n")
+
+	histogram := aggregate.Histogram(aggregateBins)
+	max := 0
+	for _, count := range histogram {
+		if count > max {
+			max = count
+		}
+	}
+
+	text.WriteString("\nDistribution\n")
+	for bin, count := range histogram {
+		lower, upper := aggregate.BinBounds(bin, aggregateBins)
+		bar := strings.Repeat("█", count*aggregateBarWidth/max)
+		fmt.Fprintf(&text, "%9s – %-9s %s %d\n", nodes.FormatNumber(lower), nodes.FormatNumber(upper), v.colored(bar), count)
+	}
+
+	v.SetText(text.String())
+	v.ScrollToBeginning()
+	return v
+}
+
+func (v *AggregateView) colored(text string) string {
+	if v.monochrome {
+		return text
+	}
+	return "[green]" + text + "[-]"
+}
@@ -0,0 +1,80 @@
+package widgets
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/benweidig/trex/nodes"
+	"github.com/benweidig/trex/ui"
+	"github.com/rivo/tview"
+)
+
+type duplicateItem struct {
+	node  nodes.Node
+	label string
+}
+
+// Label implements interface ui.ListItem
+func (i *duplicateItem) Label() string {
+	return i.label
+}
+
+// DuplicateList shows groups of identical subtrees with their paths and sizes, candidates for
+// YAML anchors or deduplication. Selecting a path jumps to its node.
+type DuplicateList struct {
+	*ui.List
+
+	monochrome bool
+}
+
+// NewDuplicateList builds a new DuplicateList, selectedFn is called with the chosen node.
+func NewDuplicateList(monochrome bool, selectedFn func(node nodes.Node)) *DuplicateList {
+	l := &DuplicateList{
+		List:       ui.NewList(),
+		monochrome: monochrome,
+	}
+	l.SetBorder(true)
+	l.SetRect(0, 0, 90, 24)
+
+	l.SetSelectedFn(func(idx int, item ui.ListItem) {
+		if duplicate, ok := item.(*duplicateItem); ok {
+			selectedFn(duplicate.node)
+		}
+	})
+	return l
+}
+
+// Open finds the duplicates below the root, members with the ignored keys don't count.
+func (l *DuplicateList) Open(root nodes.Node, ignoreKeys []string) *DuplicateList {
+	title := " Duplicate subtrees "
+	if len(ignoreKeys) > 0 {
+		title = fmt.Sprintf(" Duplicate subtrees, ignoring %s ", strings.Join(ignoreKeys, ", "))
+	}
+	l.SetTitle(tview.Escape(title))
+
+	groups := nodes.FindDuplicates(root, ignoreKeys)
+	if len(groups) == 0 {
+		l.SetItems([]ui.ListItem{ui.NewSimpleListItem("  No duplicates")}, false)
+		return l
+	}
+
+	var items []ui.ListItem
+	for _, group := range groups {
+		header := fmt.Sprintf(" %d× %s, %s duplicated", len(group.Nodes), nodes.FormatBytes(group.Size), nodes.FormatBytes(group.Wasted()))
+		if l.monochrome == false {
+			header = "[orange]" + header + "[-]"
+		}
+		items = append(items, &duplicateItem{
+			node:  group.Nodes[0],
+			label: header,
+		})
+		for _, node := range group.Nodes {
+			items = append(items, &duplicateItem{
+				node:  node,
+				label: "    " + tview.Escape(node.Path()),
+			})
+		}
+	}
+	l.SetItems(items, false)
+	return l
+}
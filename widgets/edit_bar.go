@@ -0,0 +1,79 @@
+package widgets
+
+import (
+	"fmt"
+
+	"github.com/benweidig/trex/nodes"
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+// EditBar is a single-lined input for editing keys and values, it's only shown while being used.
+type EditBar struct {
+	*tview.InputField
+
+	prompt    string
+	valueType nodes.ValueType
+	typed     bool
+}
+
+// NewEditBar creates a new EditBar. The doneFn is called on Enter and cancelFn on Escape.
+// Tab cycles through the value types, unless only a key is edited.
+func NewEditBar(doneFn func(text string, valueType nodes.ValueType), cancelFn func()) *EditBar {
+	b := &EditBar{
+		InputField: tview.NewInputField(),
+		valueType:  nodes.ValueTypeString,
+	}
+	b.SetFieldBackgroundColor(tview.Styles.PrimitiveBackgroundColor)
+	b.SetLabelColor(tview.Styles.SecondaryTextColor)
+
+	b.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			doneFn(b.GetText(), b.valueType)
+
+		case tcell.KeyEsc:
+			cancelFn()
+
+		case tcell.KeyTab:
+			if b.typed == false {
+				return
+			}
+			for idx, valueType := range nodes.ValueTypes {
+				if valueType == b.valueType {
+					b.valueType = nodes.ValueTypes[(idx+1)%len(nodes.ValueTypes)]
+					break
+				}
+			}
+			b.updateLabel()
+		}
+	})
+	return b
+}
+
+// StartKey prepares the bar for editing a key.
+func (b *EditBar) StartKey(prompt string, key string) *EditBar {
+	b.prompt = prompt
+	b.typed = false
+	b.SetText(key)
+	b.updateLabel()
+	return b
+}
+
+// StartValue prepares the bar for editing a value of the type.
+func (b *EditBar) StartValue(prompt string, text string, valueType nodes.ValueType) *EditBar {
+	b.prompt = prompt
+	b.typed = true
+	b.valueType = valueType
+	b.SetText(text)
+	b.updateLabel()
+	return b
+}
+
+func (b *EditBar) updateLabel() {
+	if b.typed {
+		b.SetLabel(fmt.Sprintf("%s (%s): ", b.prompt, b.valueType))
+	} else {
+		b.SetLabel(b.prompt + ": ")
+	}
+}
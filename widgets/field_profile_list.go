@@ -0,0 +1,96 @@
+package widgets
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/benweidig/trex/nodes"
+	"github.com/benweidig/trex/ui"
+	"github.com/rivo/tview"
+)
+
+// fieldProfileTopN is the count of most common values listed per field.
+const fieldProfileTopN = 3
+
+type fieldProfileItem struct {
+	node  nodes.Node
+	label string
+}
+
+// Label implements interface ui.ListItem
+func (i *fieldProfileItem) Label() string {
+	return i.label
+}
+
+// FieldProfileList lists every key path of the elements of an array with its presence, types,
+// distinct value count and most common values. Selecting a field jumps to its first node.
+type FieldProfileList struct {
+	*ui.List
+
+	monochrome bool
+}
+
+// NewFieldProfileList builds a new FieldProfileList, selectedFn is called with the first node of the chosen field.
+func NewFieldProfileList(monochrome bool, selectedFn func(node nodes.Node)) *FieldProfileList {
+	l := &FieldProfileList{
+		List:       ui.NewList(),
+		monochrome: monochrome,
+	}
+	l.SetBorder(true)
+	l.SetRect(0, 0, 110, 24)
+
+	l.SetSelectedFn(func(idx int, item ui.ListItem) {
+		if field, ok := item.(*fieldProfileItem); ok {
+			selectedFn(field.node)
+		}
+	})
+	return l
+}
+
+// Open profiles the fields of the elements of the array.
+func (l *FieldProfileList) Open(array nodes.Node) error {
+	profiles, err := nodes.ProfileFields(array, fieldProfileTopN)
+	if err != nil {
+		return err
+	}
+	elements := len(array.Children())
+	l.SetTitle(fmt.Sprintf(" Field profile of %s (%d elements) ", tview.Escape(array.Path()), elements))
+
+	if len(profiles) == 0 {
+		l.SetItems([]ui.ListItem{ui.NewSimpleListItem("  No objects with keys")}, false)
+		return nil
+	}
+
+	items := make([]ui.ListItem, len(profiles))
+	for idx, profile := range profiles {
+		items[idx] = &fieldProfileItem{
+			node:  profile.First,
+			label: l.itemLabel(profile, elements),
+		}
+	}
+	l.SetItems(items, false)
+	return nil
+}
+
+func (l *FieldProfileList) itemLabel(profile nodes.FieldProfile, elements int) string {
+	types := make([]string, len(profile.Types))
+	for idx, valueType := range profile.Types {
+		types[idx] = valueType.Value
+	}
+
+	top := make([]string, len(profile.Top))
+	for idx, value := range profile.Top {
+		top[idx] = fmt.Sprintf("%s (%d)", value.Value, value.Count)
+	}
+
+	path := tview.Escape(fmt.Sprintf("%-30s", profile.Path))
+	if l.monochrome == false {
+		path = "[orange]" + path + "[-]"
+	}
+	return fmt.Sprintf(" %s %5.1f%%  %-22s %6d distinct  %s",
+		path,
+		float64(profile.Present)*100/float64(elements),
+		strings.Join(types, "|"),
+		profile.Distinct,
+		tview.Escape(strings.Join(top, ", ")))
+}
@@ -0,0 +1,61 @@
+package widgets
+
+import (
+	"fmt"
+
+	"github.com/benweidig/trex/nodes"
+	"github.com/benweidig/trex/ui"
+	"github.com/rivo/tview"
+)
+
+// HistoryList shows all recorded changes of a tree, undone changes are grayed out.
+// Selecting an item undoes/redoes the changes until it's the latest applied one.
+type HistoryList struct {
+	*ui.List
+
+	monochrome bool
+}
+
+// NewHistoryList builds a new HistoryList, selectedFn is called with the count of changes to apply.
+func NewHistoryList(monochrome bool, selectedFn func(applied int)) *HistoryList {
+	l := &HistoryList{
+		List:       ui.NewList(),
+		monochrome: monochrome,
+	}
+	l.SetBorder(true)
+	l.SetTitle(" History ")
+	l.SetRect(0, 0, 70, 16)
+
+	l.SetSelectedFn(func(idx int, item ui.ListItem) {
+		selectedFn(idx)
+	})
+	return l
+}
+
+// Open displays the changes of the history and selects the latest applied one.
+func (l *HistoryList) Open(history *nodes.History) *HistoryList {
+	// The first item is the state before all changes, so the list is never empty
+	items := []ui.ListItem{
+		ui.NewSimpleListItem(l.itemLabel("<loaded>", "", history.Applied() == 0, false)),
+	}
+	for idx, command := range history.Commands() {
+		label := l.itemLabel(command.Description, command.Path, history.Applied() == idx+1, idx >= history.Applied())
+		items = append(items, ui.NewSimpleListItem(label))
+	}
+
+	l.SetItems(items, false)
+	l.SetCurrentItem(history.Applied())
+	return l
+}
+
+func (l *HistoryList) itemLabel(description string, path string, current bool, undone bool) string {
+	marker := "  "
+	if current {
+		marker = "> "
+	}
+	label := fmt.Sprintf("%s%-30s %s", marker, tview.Escape(description), tview.Escape(path))
+	if undone && l.monochrome == false {
+		label = "[gray]" + label + "[-]"
+	}
+	return label
+}
@@ -3,12 +3,16 @@ package widgets
 import "github.com/rivo/tview"
 
 // NewMainPage builds the promitive representing the app.
-func NewMainPage(ratio int, nodeList *NodeList, output *Output, statusBar *StatusBar) (page tview.Primitive, topContentFlex *tview.Flex) {
+// The query, search and edit bars are hidden initially, resize them to a height of 1 to show them.
+func NewMainPage(ratio int, nodeList *NodeList, output *Output, queryBar *QueryBar, searchBar *SearchBar, editBar *EditBar, statusBar *StatusBar) (page *tview.Flex, topContentFlex *tview.Flex) {
 	flex := tview.NewFlex().
 		AddItem(nodeList, 0, ratio, true).
 		AddItem(output, 0, 10, false)
 	return tview.NewFlex().
 		SetDirection(tview.FlexRow).
 		AddItem(flex, 0, 1, true).
+		AddItem(queryBar, 0, 0, false).
+		AddItem(searchBar, 0, 0, false).
+		AddItem(editBar, 0, 0, false).
 		AddItem(statusBar, 1, 0, false), flex
 }
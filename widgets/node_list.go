@@ -1,6 +1,7 @@
 package widgets
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/benweidig/trex/nodes"
@@ -25,6 +26,21 @@ type NodeList struct {
 	monochrome bool
 	root       nodes.Node
 
+	// matches can be navigated, and optionally filter the list to them and their ancestors
+	matches  []nodes.Node
+	matchSet map[nodes.Node]bool
+	visible  map[nodes.Node]bool
+	backward bool
+
+	// highlight marks the occurrences of a search in the labels of the matches
+	highlight *nodes.Matcher
+
+	// invalid contains the count of schema violations of nodes, they get an error badge
+	invalid map[nodes.Node]int
+
+	// sortBySize displays the largest children first, the document order is untouched
+	sortBySize bool
+
 	changedFn func(node nodes.Node)
 	done      func()
 }
@@ -58,13 +74,238 @@ func (nl *NodeList) SetRoot(root nodes.Node) *NodeList {
 
 	nl.ClearItems()
 
-	nl.buildNodes(nl.root, 0)
+	nl.buildNodes(nl.root, 0, nl.visible != nil)
 	nl.SetItems(nl.GetItems(), true)
 
 	return nl
 }
 
-func (nl *NodeList) buildNodes(node nodes.Node, indentLvl int) {
+// GetRoot returns the node currently displayed as the root element.
+func (nl *NodeList) GetRoot() nodes.Node {
+	return nl.root
+}
+
+// SetMatches sets the nodes that can be navigated with n/N, and expands their ancestors.
+// If filter is true only the matches, their ancestors and their children are displayed.
+func (nl *NodeList) SetMatches(matches []nodes.Node, filter bool) *NodeList {
+	nl.matches = matches
+	nl.matchSet = make(map[nodes.Node]bool, len(matches))
+	nl.visible = nil
+	nl.backward = false
+	if filter {
+		nl.visible = make(map[nodes.Node]bool)
+	}
+
+	for _, match := range matches {
+		nl.matchSet[match] = true
+		for ancestor := match.Parent(); ancestor != nil; ancestor = ancestor.Parent() {
+			if ancestor.IsCollapsed() {
+				ancestor.ToggleExpansion()
+			}
+			if filter {
+				nl.visible[ancestor] = true
+			}
+		}
+	}
+
+	nl.SetRoot(nl.root)
+	if len(matches) > 0 {
+		nl.SelectNode(matches[0])
+	}
+	return nl
+}
+
+// ClearMatches removes all matches, the filter and the highlight.
+func (nl *NodeList) ClearMatches() *NodeList {
+	nl.highlight = nil
+	return nl.SetMatches(nil, false)
+}
+
+// SetHighlight sets the matcher for highlighting the labels of matches, nil disables it.
+func (nl *NodeList) SetHighlight(m *nodes.Matcher) *NodeList {
+	nl.highlight = m
+	return nl
+}
+
+// GetHighlight returns the matcher used for highlighting, might be nil.
+func (nl *NodeList) GetHighlight() *nodes.Matcher {
+	return nl.highlight
+}
+
+// SetBackward reverses the direction of n/N.
+func (nl *NodeList) SetBackward(backward bool) *NodeList {
+	nl.backward = backward
+	return nl
+}
+
+// SelectMatchFrom selects the first match starting at the node (including itself),
+// forward or backward in the list.
+func (nl *NodeList) SelectMatchFrom(node nodes.Node, backward bool) *NodeList {
+	nl.SelectNode(node)
+	if nl.matchSet[node] {
+		return nl
+	}
+	if idx, found := nl.findMatch(backward == false); found {
+		nl.SetCurrentItem(idx)
+	}
+	return nl
+}
+
+// GetMatchPosition returns the 1-based position of the current node in the matches,
+// or 0 if it isn't a match, and the total count of matches.
+func (nl *NodeList) GetMatchPosition() (int, int) {
+	current := nl.GetCurrentNode()
+	for idx, match := range nl.matches {
+		if match == current {
+			return idx + 1, len(nl.matches)
+		}
+	}
+	return 0, len(nl.matches)
+}
+
+// SetInvalid sets the count of schema violations per node, they are marked with an error badge.
+func (nl *NodeList) SetInvalid(invalid map[nodes.Node]int) *NodeList {
+	nl.invalid = invalid
+	return nl
+}
+
+// SetSortBySize toggles displaying the largest children first.
+func (nl *NodeList) SetSortBySize(sortBySize bool) *NodeList {
+	current := nl.GetCurrentNode()
+	nl.sortBySize = sortBySize
+	nl.SetRoot(nl.root)
+	return nl.SelectNode(current)
+}
+
+// IsSortedBySize reports whether the largest children are displayed first.
+func (nl *NodeList) IsSortedBySize() bool {
+	return nl.sortBySize
+}
+
+// SetSubtreeExpansion expands or collapses the node and all its descendants.
+func (nl *NodeList) SetSubtreeExpansion(node nodes.Node, expanded bool) *NodeList {
+	nodes.SetExpansion(node, expanded)
+	nl.SetRoot(nl.root)
+	return nl.SelectNode(node)
+}
+
+// ExpandToDepth shows the levels of the tree down to the depth, 0 expands everything.
+// The current node stays selected if it's still visible, otherwise its visible ancestor.
+func (nl *NodeList) ExpandToDepth(depth int) *NodeList {
+	current := nl.GetCurrentNode()
+	nodes.ExpandToDepth(nl.root, depth)
+	nl.SetRoot(nl.root)
+
+	for ancestor := current.Parent(); ancestor != nil; ancestor = ancestor.Parent() {
+		if ancestor.IsCollapsed() {
+			current = ancestor
+		}
+	}
+	return nl.SelectNode(current)
+}
+
+// SelectNode expands all collapsed ancestors of the node and makes it the current item.
+func (nl *NodeList) SelectNode(node nodes.Node) *NodeList {
+	var expanded bool
+	for ancestor := node.Parent(); ancestor != nil; ancestor = ancestor.Parent() {
+		if ancestor.IsCollapsed() {
+			ancestor.ToggleExpansion()
+			expanded = true
+		}
+	}
+	if expanded {
+		nl.SetRoot(nl.root)
+	}
+
+	for idx, item := range nl.GetItems() {
+		if item.(*nodeItem).node == node {
+			nl.SetCurrentItem(idx)
+			break
+		}
+	}
+	return nl
+}
+
+// findMatch returns the index of the next/previous visible match, starting at the current item.
+func (nl *NodeList) findMatch(forward bool) (int, bool) {
+	items := nl.GetItems()
+	if len(nl.matches) == 0 || len(items) == 0 {
+		return 0, false
+	}
+
+	step := 1
+	if forward == false {
+		step = -1
+	}
+	idx := nl.GetCurrentIdx()
+	for range items {
+		idx = (idx + step + len(items)) % len(items)
+		if nl.matchSet[items[idx].(*nodeItem).node] {
+			return idx, true
+		}
+	}
+	return 0, false
+}
+
+// findChild returns the index of the first/last displayed child of the current node,
+// it's expanded if necessary.
+func (nl *NodeList) findChild(last bool) (int, bool) {
+	node := nl.GetCurrentNode()
+	if node.IsCollapsable() == false {
+		return 0, false
+	}
+	if node.IsCollapsed() {
+		nl.toggle(node)
+	}
+
+	items := nl.GetItems()
+	idx, found := 0, false
+	for candidate := nl.GetCurrentIdx() + 1; candidate < len(items); candidate++ {
+		child := items[candidate].(*nodeItem).node
+		if nodes.IsDescendant(child, node) == false {
+			break
+		}
+		if child.Parent() == node {
+			idx, found = candidate, true
+			if last == false {
+				break
+			}
+		}
+	}
+	return idx, found
+}
+
+// findSibling returns the index of the next/previous displayed sibling of the current node,
+// skipping the expanded subtrees in between.
+func (nl *NodeList) findSibling(forward bool) (int, bool) {
+	node := nl.GetCurrentNode()
+	parent := node.Parent()
+	if node == nl.root || parent == nil {
+		return 0, false
+	}
+
+	step := 1
+	if forward == false {
+		step = -1
+	}
+	items := nl.GetItems()
+	for idx := nl.GetCurrentIdx() + step; idx >= 0 && idx < len(items); idx += step {
+		candidate := items[idx].(*nodeItem).node
+		if candidate.Parent() == parent {
+			return idx, true
+		}
+		if nodes.IsDescendant(candidate, parent) == false {
+			break
+		}
+	}
+	return 0, false
+}
+
+func (nl *NodeList) buildNodes(node nodes.Node, indentLvl int, filtered bool) {
+	if filtered && nl.visible[node] == false && nl.matchSet[node] == false {
+		return
+	}
+
 	var indention string
 	if indentLvl > 0 {
 		indention = strings.Repeat("  ", indentLvl)
@@ -81,8 +322,12 @@ func (nl *NodeList) buildNodes(node nodes.Node, indentLvl int) {
 	} else {
 		expansionIndicator = "  "
 	}
+	label := node.Label()
+	if nl.highlight != nil && nl.monochrome == false && nl.matchSet[node] {
+		label = label.Highlighted(nl.highlight)
+	}
 	item := &nodeItem{
-		label: indention + expansionIndicator + node.Label().String(nl.monochrome),
+		label: indention + expansionIndicator + label.String(nl.monochrome) + nl.errorBadge(node),
 		node:  node,
 	}
 	nl.AddItem(item)
@@ -91,9 +336,34 @@ func (nl *NodeList) buildNodes(node nodes.Node, indentLvl int) {
 		return
 	}
 
-	for _, child := range node.Children() {
-		nl.buildNodes(child, indentLvl+1)
+	// The whole content of matches is displayed
+	if nl.matchSet[node] {
+		filtered = false
+	}
+	children := node.Children()
+	if nl.sortBySize {
+		children = nodes.SortedBySize(children)
+	}
+	for _, child := range children {
+		nl.buildNodes(child, indentLvl+1, filtered)
+	}
+}
+
+// errorBadge marks nodes with schema violations, with the count if there are multiple.
+func (nl *NodeList) errorBadge(node nodes.Node) string {
+	count := nl.invalid[node]
+	if count == 0 {
+		return ""
+	}
+
+	badge := "✗"
+	if count > 1 {
+		badge = fmt.Sprintf("✗%d", count)
+	}
+	if nl.monochrome {
+		return " " + badge
 	}
+	return " [red]" + badge + "[-]"
 }
 
 // SetChangedFn sets the handler callback on change events.
@@ -153,6 +423,60 @@ func (nl *NodeList) setupKeyBindings() {
 					newIndex++
 				}
 				handled = true
+
+			case '^':
+				if node := nl.GetCurrentNode(); node != nl.root && node.Parent() != nil {
+					nl.SelectNode(node.Parent())
+					newIndex = nl.GetCurrentIdx()
+				}
+				handled = true
+
+			case '[', ']':
+				if idx, found := nl.findChild(event.Rune() == ']'); found {
+					newIndex = idx
+				}
+				handled = true
+
+			case '{', '}':
+				if idx, found := nl.findSibling(event.Rune() == '}'); found {
+					newIndex = idx
+				}
+				handled = true
+
+			case '<', '>':
+				if node := nodes.NextWithKey(nl.root, nl.GetCurrentNode(), event.Rune() == '<'); node != nil {
+					nl.SelectNode(node)
+					newIndex = nl.GetCurrentIdx()
+				}
+				handled = true
+
+			case '+', '-':
+				nl.SetSubtreeExpansion(nl.GetCurrentNode(), event.Rune() == '+')
+				newIndex = nl.GetCurrentIdx()
+				handled = true
+
+			case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+				nl.ExpandToDepth(int(event.Rune() - '0'))
+				newIndex = nl.GetCurrentIdx()
+				handled = true
+
+			case 'd':
+				node := nl.GetCurrentNode()
+				node.ToggleDecoding()
+				nl.SetRoot(nl.root)
+				handled = true
+
+			case 'n':
+				if idx, found := nl.findMatch(nl.backward == false); found {
+					newIndex = idx
+				}
+				handled = true
+
+			case 'N':
+				if idx, found := nl.findMatch(nl.backward); found {
+					newIndex = idx
+				}
+				handled = true
 			}
 		}
 		if handled == false {
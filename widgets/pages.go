@@ -4,9 +4,39 @@ const (
 	// MainPage Key
 	MainPage = "widgets.page.main"
 
+	// SizePage Key
+	SizePage = "widgets.page.size"
+
+	// TablePage Key
+	TablePage = "widgets.page.table"
+
 	// FormatterPopupPage Key
 	FormatterPopupPage = "widgets.page.formatter-popup"
 
+	// PathSyntaxPopupPage Key
+	PathSyntaxPopupPage = "widgets.page.path-syntax-popup"
+
+	// PathFinderPopupPage Key
+	PathFinderPopupPage = "widgets.page.path-finder-popup"
+
+	// HistoryPopupPage Key
+	HistoryPopupPage = "widgets.page.history-popup"
+
+	// ViolationPopupPage Key
+	ViolationPopupPage = "widgets.page.violation-popup"
+
+	// AggregatePopupPage Key
+	AggregatePopupPage = "widgets.page.aggregate-popup"
+
+	// FieldProfilePopupPage Key
+	FieldProfilePopupPage = "widgets.page.field-profile-popup"
+
+	// DuplicatePopupPage Key
+	DuplicatePopupPage = "widgets.page.duplicate-popup"
+
 	// HelpPopupPage Key
 	HelpPopupPage = "widgets.page.help-popup"
+
+	// QuitConfirmPage Key
+	QuitConfirmPage = "widgets.page.quit-confirm"
 )
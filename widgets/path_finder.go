@@ -0,0 +1,163 @@
+package widgets
+
+import (
+	"strings"
+
+	"github.com/benweidig/trex/nodes"
+	"github.com/benweidig/trex/ui"
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+type pathFinderItem struct {
+	match nodes.FuzzyMatch
+	label string
+}
+
+// Label implements interface ui.ListItem
+func (i *pathFinderItem) Label() string {
+	return i.label
+}
+
+// PathFinder is a fzf-like popup for finding nodes by their paths.
+// The input keeps the focus, navigation keys are passed to the result list.
+type PathFinder struct {
+	*tview.Flex
+
+	input   *tview.InputField
+	results *ui.List
+
+	monochrome bool
+	index      *nodes.FuzzyIndex
+}
+
+// NewPathFinder builds a new PathFinder, selectedFn is called with the chosen node.
+func NewPathFinder(monochrome bool, selectedFn func(node nodes.Node)) *PathFinder {
+	f := &PathFinder{
+		Flex:       tview.NewFlex(),
+		input:      tview.NewInputField(),
+		results:    ui.NewList(),
+		monochrome: monochrome,
+	}
+
+	f.input.SetLabel("> ")
+	f.input.SetFieldBackgroundColor(tview.Styles.PrimitiveBackgroundColor)
+	f.input.SetLabelColor(tview.Styles.SecondaryTextColor)
+	f.input.SetChangedFunc(func(text string) {
+		f.update()
+	})
+
+	f.results.SetSelectedFn(func(idx int, item ui.ListItem) {
+		if result, ok := item.(*pathFinderItem); ok {
+			selectedFn(result.match.Node)
+		}
+	})
+
+	f.SetDirection(tview.FlexRow).
+		AddItem(f.input, 1, 0, true).
+		AddItem(f.results, 0, 1, false)
+	f.SetBorder(true)
+	f.SetTitle(" Find path ")
+	f.SetRect(0, 0, 80, 20)
+
+	return f
+}
+
+// Open resets the query and shows all paths of the root.
+func (f *PathFinder) Open(root nodes.Node, syntax nodes.PathSyntax) *PathFinder {
+	f.index = nodes.NewFuzzyIndex(root, syntax)
+	f.input.SetText("")
+	f.update()
+	return f
+}
+
+func (f *PathFinder) update() {
+	if f.index == nil {
+		return
+	}
+
+	matches := f.index.Find(f.input.GetText())
+	if len(matches) == 0 {
+		f.results.SetItems([]ui.ListItem{ui.NewSimpleListItem("  No matches")}, false)
+		return
+	}
+
+	items := make([]ui.ListItem, len(matches))
+	for idx, match := range matches {
+		items[idx] = &pathFinderItem{
+			match: match,
+			label: " " + f.highlight(match),
+		}
+	}
+	f.results.SetItems(items, false)
+}
+
+// highlight colors the matched characters, every segment is escaped on its own,
+// so the tags don't interfere with brackets in the path.
+func (f *PathFinder) highlight(match nodes.FuzzyMatch) string {
+	if f.monochrome {
+		return tview.Escape(match.Path)
+	}
+
+	highlighted := make(map[int]bool, len(match.Positions))
+	for _, pos := range match.Positions {
+		highlighted[pos] = true
+	}
+
+	var builder strings.Builder
+	var segment []rune
+	segmentHighlighted := false
+	flush := func() {
+		if len(segment) == 0 {
+			return
+		}
+		if segmentHighlighted {
+			builder.WriteString("[orange]" + tview.Escape(string(segment)) + "[-]")
+		} else {
+			builder.WriteString(tview.Escape(string(segment)))
+		}
+		segment = segment[:0]
+	}
+
+	for idx, r := range []rune(match.Path) {
+		if highlighted[idx] != segmentHighlighted {
+			flush()
+			segmentHighlighted = highlighted[idx]
+		}
+		segment = append(segment, r)
+	}
+	flush()
+
+	return builder.String()
+}
+
+// InputHandler implements tview.Primitive
+func (f *PathFinder) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return f.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+		switch event.Key() {
+		case tcell.KeyUp, tcell.KeyDown, tcell.KeyPgUp, tcell.KeyPgDn, tcell.KeyEnter:
+			f.results.InputHandler()(event, setFocus)
+
+		default:
+			f.input.InputHandler()(event, setFocus)
+		}
+	})
+}
+
+// Focus implements tview.Primitive, the input is marked as focused without delegating,
+// so all events still arrive at the PathFinder.
+func (f *PathFinder) Focus(delegate func(p tview.Primitive)) {
+	f.input.Focus(delegate)
+	f.results.Focus(delegate)
+}
+
+// Blur implements tview.Primitive
+func (f *PathFinder) Blur() {
+	f.input.Blur()
+	f.results.Blur()
+}
+
+// GetFocusable implements tview.Primitive
+func (f *PathFinder) GetFocusable() tview.Focusable {
+	return f.input.GetFocusable()
+}
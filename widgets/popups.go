@@ -1,19 +1,25 @@
 package widgets
 
 import (
+	"github.com/benweidig/trex/codegen"
 	"github.com/benweidig/trex/input"
+	"github.com/benweidig/trex/nodes"
 	"github.com/benweidig/trex/ui"
 	"github.com/rivo/tview"
 )
 
-// NewFormatterPopup builds a new tview.Primitive for the formatter chooser
-func NewFormatterPopup(selectedFn func(fileType input.FileType)) tview.Primitive {
+// NewFormatterPopup builds a new tview.Primitive for the formatter chooser.
+// The code generators are listed as pseudo-formatters after the file types.
+func NewFormatterPopup(selectedFn func(fileType input.FileType), codeFn func(language codegen.Language)) tview.Primitive {
 	l := ui.NewList()
-	l.SetRect(0, 0, 10, 4)
+	l.SetRect(0, 0, 16, 4+len(codegen.Languages))
 	items := []ui.ListItem{
 		ui.NewSimpleListItem("  JSON  "),
 		ui.NewSimpleListItem("  YAML  "),
 	}
+	for _, language := range codegen.Languages {
+		items = append(items, ui.NewSimpleListItem("  "+string(language)+"  "))
+	}
 	l.SetItems(items, false)
 	l.SetBorder(true)
 	l.SetTitle("Output")
@@ -25,17 +31,72 @@ func NewFormatterPopup(selectedFn func(fileType input.FileType)) tview.Primitive
 
 		case 1:
 			selectedFn(input.FileTypeYAML)
+
+		default:
+			codeFn(codegen.Languages[idx-2])
 		}
 	})
 
 	return ui.NewPopup(l)
 }
 
+// NewPathSyntaxPopup builds a new tview.Primitive for the path syntax chooser
+func NewPathSyntaxPopup(selectedFn func(syntax nodes.PathSyntax)) tview.Primitive {
+	l := ui.NewList()
+	l.SetRect(0, 0, 17, len(nodes.PathSyntaxes)+2)
+	items := make([]ui.ListItem, len(nodes.PathSyntaxes))
+	for idx, syntax := range nodes.PathSyntaxes {
+		items[idx] = ui.NewSimpleListItem("  " + string(syntax) + "  ")
+	}
+	l.SetItems(items, false)
+	l.SetBorder(true)
+	l.SetTitle("Path")
+
+	l.SetSelectedFn(func(idx int, item ui.ListItem) {
+		selectedFn(nodes.PathSyntaxes[idx])
+	})
+
+	return ui.NewPopup(l)
+}
+
 const helpPopupText = `(shift + ←/→) Resize
         (tab) Switch focus (tree/output)
-          (f) Choose Formatter
+          (f) Choose Formatter or code generator
           (c) Copy currently selected node
-          (?) Display help
+          (p) Copy path of selected node
+          (P) Choose path syntax
+          (d) Decode string (base64, JWT, URL)
+        (+/-) Expand/collapse subtree
+          (^) Jump to parent
+        ([ ]) Jump to first/last child
+        ({ }) Jump to previous/next sibling
+        (< >) Previous/next node with same key
+        (1-9) Expand to depth, (0) expand all
+          (S) Sort children by size
+          (D) Size breakdown (enter/backspace)
+          (T) Table of array (s)ort, (x) hide
+      (O/F/G) Sort/filter/group array elements
+          (A) Aggregate numbers of array
+          (R) Field profile of array
+          (U) Find duplicate subtrees
+          (e) Open file at node in $EDITOR
+          (:) Query: JSONPath/jq/JMESPath (tab)
+  (backspace) Undo last filter/derived view
+        (/ ?) Search forward/backward (tab)
+        (n/N) Next/previous match
+   (ctrl + p) Find path (fuzzy)
+          (C) Show only changes (diff/merge)
+          (V) Schema violations
+          (I) Infer schema of selected node
+      (b/o/t) Pick base/ours/theirs (merge)
+          (w) Write merge result
+          (i) Toggle edit mode, then edit (v)alue,
+              (r)ename key, (a)dd, (x) delete,
+              (y) duplicate, (J/K) move, (s)ave
+          (u) Undo last change or filter
+   (ctrl + r) Redo
+          (H) Browse history of changes
+         (F1) Display help
 
 Navigate with arrow keys / vim-keys`
 
@@ -44,9 +105,21 @@ func NewHelpPopup() tview.Primitive {
 	t := tview.NewTextView()
 	t.SetBorder(true)
 	t.SetTitle(" Help ")
-	t.SetRect(0, 0, 47, 9)
+	t.SetRect(0, 0, 52, 44)
 	t.SetBorderPadding(1, 1, 1, 1)
 	t.SetText(helpPopupText)
 
 	return ui.NewPopup(t)
 }
+
+// NewQuitConfirmation builds a tview.Primitive asking what to do with unsaved changes.
+// The doneFn receives "Save", "Discard" or "Cancel".
+func NewQuitConfirmation(doneFn func(label string)) tview.Primitive {
+	m := tview.NewModal()
+	m.SetText("There are unsaved changes.\nSave them before quitting?")
+	m.AddButtons([]string{"Save", "Discard", "Cancel"})
+	m.SetDoneFunc(func(idx int, label string) {
+		doneFn(label)
+	})
+	return m
+}
@@ -0,0 +1,77 @@
+package widgets
+
+import (
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+// QueryLanguage is the language used for interpreting a query.
+type QueryLanguage string
+
+const (
+	// QueryLanguageJSONPath filters the tree by the matches of a JSONPath
+	QueryLanguageJSONPath QueryLanguage = "JSONPath"
+
+	// QueryLanguageJQ replaces the tree with the result of a jq filter
+	QueryLanguageJQ QueryLanguage = "jq"
+
+	// QueryLanguageJMESPath replaces the tree with the result of a JMESPath expression
+	QueryLanguageJMESPath QueryLanguage = "JMESPath"
+)
+
+// QueryLanguages contains all query languages in the order they are cycled through.
+var QueryLanguages = []QueryLanguage{
+	QueryLanguageJSONPath,
+	QueryLanguageJQ,
+	QueryLanguageJMESPath,
+}
+
+// QueryBar is a single-lined input for queries, it's only shown while being used.
+type QueryBar struct {
+	*tview.InputField
+
+	language QueryLanguage
+}
+
+// NewQueryBar creates a new QueryBar. The doneFn is called with the language and query
+// on Enter, cancelFn on Escape. Tab cycles through the query languages.
+func NewQueryBar(doneFn func(language QueryLanguage, query string), cancelFn func()) *QueryBar {
+	b := &QueryBar{
+		InputField: tview.NewInputField(),
+	}
+	b.SetLanguage(QueryLanguageJSONPath)
+	b.SetFieldBackgroundColor(tview.Styles.PrimitiveBackgroundColor)
+	b.SetLabelColor(tview.Styles.SecondaryTextColor)
+
+	b.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			doneFn(b.language, b.GetText())
+
+		case tcell.KeyEsc:
+			b.SetText("")
+			cancelFn()
+
+		case tcell.KeyTab:
+			for idx, language := range QueryLanguages {
+				if language == b.language {
+					b.SetLanguage(QueryLanguages[(idx+1)%len(QueryLanguages)])
+					break
+				}
+			}
+		}
+	})
+	return b
+}
+
+// GetLanguage returns the current query language.
+func (b *QueryBar) GetLanguage() QueryLanguage {
+	return b.language
+}
+
+// SetLanguage changes the query language, the label reflects the language.
+func (b *QueryBar) SetLanguage(language QueryLanguage) *QueryBar {
+	b.language = language
+	b.SetLabel(string(language) + ": ")
+	return b
+}
@@ -0,0 +1,75 @@
+package widgets
+
+import (
+	"fmt"
+
+	"github.com/benweidig/trex/nodes"
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+// SearchBar is a single-lined input for incremental searches, it's only shown while being used.
+type SearchBar struct {
+	*tview.InputField
+
+	mode     nodes.SearchMode
+	backward bool
+}
+
+// NewSearchBar creates a new SearchBar. The changedFn is called on every change of the pattern
+// or mode, doneFn on Enter and cancelFn on Escape. Tab cycles through the search modes.
+func NewSearchBar(changedFn func(pattern string, mode nodes.SearchMode), doneFn func(), cancelFn func()) *SearchBar {
+	b := &SearchBar{
+		InputField: tview.NewInputField(),
+		mode:       nodes.SearchModePlain,
+	}
+	b.updateLabel()
+	b.SetFieldBackgroundColor(tview.Styles.PrimitiveBackgroundColor)
+	b.SetLabelColor(tview.Styles.SecondaryTextColor)
+
+	b.SetChangedFunc(func(text string) {
+		changedFn(text, b.mode)
+	})
+
+	b.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			doneFn()
+
+		case tcell.KeyEsc:
+			cancelFn()
+
+		case tcell.KeyTab:
+			for idx, mode := range nodes.SearchModes {
+				if mode == b.mode {
+					b.mode = nodes.SearchModes[(idx+1)%len(nodes.SearchModes)]
+					break
+				}
+			}
+			b.updateLabel()
+			changedFn(b.GetText(), b.mode)
+		}
+	})
+	return b
+}
+
+// Start resets the pattern and sets the direction of the search.
+func (b *SearchBar) Start(backward bool) *SearchBar {
+	b.backward = backward
+	b.SetText("")
+	b.updateLabel()
+	return b
+}
+
+// IsBackward reports whether the search goes backward.
+func (b *SearchBar) IsBackward() bool {
+	return b.backward
+}
+
+func (b *SearchBar) updateLabel() {
+	prompt := "/"
+	if b.backward {
+		prompt = "?"
+	}
+	b.SetLabel(fmt.Sprintf("%s (%s) ", prompt, b.mode))
+}
@@ -0,0 +1,153 @@
+package widgets
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/benweidig/trex/nodes"
+	"github.com/benweidig/trex/ui"
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+const sizeBarWidth = 20
+
+type sizeItem struct {
+	node  nodes.Node
+	label string
+}
+
+// Label implements interface ui.ListItem
+func (i *sizeItem) Label() string {
+	return i.label
+}
+
+// SizeBreakdown is an ncdu-like breakdown of where the bytes of a node go. Every child is listed
+// with its serialized size, percentage and a proportional bar, the largest first.
+// Enter drills into a child, Backspace goes up to the parent.
+type SizeBreakdown struct {
+	*tview.Flex
+
+	header *tview.TextView
+	list   *ui.List
+	footer *tview.TextView
+
+	monochrome bool
+	node       nodes.Node
+}
+
+// NewSizeBreakdown builds a new empty SizeBreakdown.
+func NewSizeBreakdown(monochrome bool) *SizeBreakdown {
+	p := &SizeBreakdown{
+		Flex:       tview.NewFlex(),
+		header:     tview.NewTextView(),
+		list:       ui.NewList(),
+		footer:     tview.NewTextView(),
+		monochrome: monochrome,
+	}
+	p.header.SetDynamicColors(true)
+	p.header.SetTextColor(tview.Styles.PrimitiveBackgroundColor)
+	p.header.SetBackgroundColor(tview.Styles.PrimaryTextColor)
+	p.footer.SetText(" (enter) Open  (backspace) Up  (esc) Back to tree")
+	p.footer.SetTextColor(tview.Styles.SecondaryTextColor)
+
+	p.list.SetSelectedFn(func(idx int, item ui.ListItem) {
+		if child, ok := item.(*sizeItem); ok && nodes.IsContainer(child.node) {
+			p.Open(child.node)
+		}
+	})
+
+	p.SetDirection(tview.FlexRow).
+		AddItem(p.header, 1, 0, false).
+		AddItem(p.list, 0, 1, true).
+		AddItem(p.footer, 1, 0, false)
+	return p
+}
+
+// Open displays the children of the node, scalars display their parent instead.
+func (p *SizeBreakdown) Open(node nodes.Node) *SizeBreakdown {
+	for nodes.IsContainer(node) == false && node.Parent() != nil {
+		node = node.Parent()
+	}
+	p.node = node
+
+	stats := nodes.Measure(node)
+	p.header.SetText(fmt.Sprintf(" %s  %s", tview.Escape(node.Path()), stats))
+
+	children := nodes.SortedBySize(node.Children())
+	if len(children) == 0 {
+		p.list.SetItems([]ui.ListItem{ui.NewSimpleListItem("  Empty")}, false)
+		return p
+	}
+
+	items := make([]ui.ListItem, len(children))
+	for idx, child := range children {
+		items[idx] = &sizeItem{
+			node:  child,
+			label: p.itemLabel(child, stats.Size),
+		}
+	}
+	p.list.SetItems(items, false)
+	p.list.SetCurrentItem(0)
+	return p
+}
+
+// GetNode returns the selected child, or the displayed node if it has no children.
+func (p *SizeBreakdown) GetNode() nodes.Node {
+	if item, ok := p.list.GetCurrentItem().(*sizeItem); ok {
+		return item.node
+	}
+	return p.node
+}
+
+func (p *SizeBreakdown) itemLabel(child nodes.Node, total int) string {
+	size := nodes.Measure(child).Size
+	ratio := float64(size) / float64(total)
+
+	filled := int(ratio*sizeBarWidth + 0.5)
+	bar := strings.Repeat("#", filled)
+	if p.monochrome == false {
+		bar = "[green]" + bar + "[-]"
+	}
+	bar += strings.Repeat("-", sizeBarWidth-filled)
+
+	return fmt.Sprintf(" %10s %5.1f%% %s  %s", nodes.FormatBytes(size), ratio*100, bar, child.Label().String(p.monochrome))
+}
+
+// InputHandler implements tview.Primitive
+func (p *SizeBreakdown) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return p.WrapInputHandler(func(event *tcell.EventKey, setFocus func(primitive tview.Primitive)) {
+		switch event.Key() {
+		case tcell.KeyBackspace, tcell.KeyBackspace2:
+			if p.node == nil || p.node.Parent() == nil {
+				return
+			}
+			previous := p.node
+			p.Open(previous.Parent())
+			for idx, item := range p.list.GetItems() {
+				if item.(*sizeItem).node == previous {
+					p.list.SetCurrentItem(idx)
+					break
+				}
+			}
+
+		default:
+			p.list.InputHandler()(event, setFocus)
+		}
+	})
+}
+
+// Focus implements tview.Primitive
+func (p *SizeBreakdown) Focus(delegate func(p tview.Primitive)) {
+	p.list.Focus(delegate)
+}
+
+// Blur implements tview.Primitive
+func (p *SizeBreakdown) Blur() {
+	p.list.Blur()
+}
+
+// GetFocusable implements tview.Primitive
+func (p *SizeBreakdown) GetFocusable() tview.Focusable {
+	return p.list.GetFocusable()
+}
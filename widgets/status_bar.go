@@ -1,6 +1,7 @@
 package widgets
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/benweidig/trex/input"
@@ -14,6 +15,9 @@ type StatusBar struct {
 	textView *tview.TextView
 	path     string
 	fileType input.FileType
+	source   string
+	position input.Position
+	info     string
 }
 
 // NewStatusBar creates a new StatusBar
@@ -46,16 +50,48 @@ func (b *StatusBar) SetFileType(fileType input.FileType) *StatusBar {
 	return b
 }
 
+// SetSource sets the name of the loaded file, empty for piped input
+func (b *StatusBar) SetSource(source string) *StatusBar {
+	b.source = source
+	return b
+}
+
+// SetPosition updates the source position of the current node
+func (b *StatusBar) SetPosition(position input.Position) *StatusBar {
+	b.position = position
+	return b
+}
+
+// SetInfo sets an additional short info, like the count of matches or an error
+func (b *StatusBar) SetInfo(info string) *StatusBar {
+	b.info = info
+	return b
+}
+
 // Draw implements tview.Primitive
 func (b *StatusBar) Draw(screen tcell.Screen) {
 	_, _, width, _ := b.textView.GetInnerRect()
 
-	fileType := string(b.fileType)
+	right := string(b.fileType)
+	if b.position.IsKnown() {
+		source := b.source
+		if len(source) == 0 {
+			source = "<stdin>"
+		}
+		right = fmt.Sprintf("%s:%d:%d  %s", source, b.position.Line, b.position.Column, right)
+	}
+	if len(b.info) > 0 {
+		right = b.info + "  " + right
+	}
+
 	actualWidth := width - 2
-	paddingWidth := actualWidth - len(b.path) - len(fileType)
+	paddingWidth := actualWidth - len(b.path) - len(right)
+	if paddingWidth < 1 {
+		paddingWidth = 1
+	}
 	padding := strings.Repeat(" ", paddingWidth)
 
-	b.textView.SetText(" " + b.path + padding + fileType)
+	b.textView.SetText(" " + b.path + padding + right)
 
 	b.textView.Draw(screen)
 }
@@ -0,0 +1,454 @@
+package widgets
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/benweidig/trex/nodes"
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+const (
+	tableMaxColumnWidth = 30
+	tableIndexWidth     = 6
+)
+
+type tableColumn struct {
+	nodes.TableColumn
+
+	title  string
+	width  int
+	hidden bool
+}
+
+// TableView renders the elements of an array as a grid, one row per element and one column per key
+// of all elements. Columns can be sorted and hidden, Enter selects the node of the cell in the tree.
+type TableView struct {
+	*tview.Flex
+
+	header *tview.TextView
+	grid   *tableGrid
+	footer *tview.TextView
+
+	array nodes.Node
+}
+
+// NewTableView builds a new empty TableView, selectedFn is called with the node of the chosen cell.
+func NewTableView(monochrome bool, selectedFn func(node nodes.Node)) *TableView {
+	t := &TableView{
+		Flex:   tview.NewFlex(),
+		header: tview.NewTextView(),
+		grid:   newTableGrid(monochrome, selectedFn),
+		footer: tview.NewTextView(),
+	}
+	t.header.SetDynamicColors(true)
+	t.header.SetTextColor(tview.Styles.PrimitiveBackgroundColor)
+	t.header.SetBackgroundColor(tview.Styles.PrimaryTextColor)
+	t.footer.SetText(" (enter) Select  (s) Sort  (x) Hide column  (a) Show all  (esc) Back to tree")
+	t.footer.SetTextColor(tview.Styles.SecondaryTextColor)
+
+	t.grid.changedFn = t.updateHeader
+
+	t.SetDirection(tview.FlexRow).
+		AddItem(t.header, 1, 0, false).
+		AddItem(t.grid, 0, 1, true).
+		AddItem(t.footer, 1, 0, false)
+	return t
+}
+
+// Open displays the elements of the array, the row of the element containing the node is selected.
+func (t *TableView) Open(array nodes.Node, node nodes.Node) *TableView {
+	t.array = array
+	t.grid.open(array)
+
+	for current := node; current != nil && current != array; current = current.Parent() {
+		if current.Parent() == array {
+			t.grid.selectElement(current)
+			break
+		}
+	}
+
+	t.updateHeader()
+	return t
+}
+
+func (t *TableView) updateHeader() {
+	if t.array == nil {
+		return
+	}
+
+	info := fmt.Sprintf("%d rows, %d columns", len(t.grid.rows), len(t.grid.columns))
+	if hidden := t.grid.hiddenCount(); hidden > 0 {
+		info += fmt.Sprintf(" (%d hidden)", hidden)
+	}
+	if t.grid.sortColumn >= 0 {
+		direction := "ascending"
+		if t.grid.sortDescending {
+			direction = "descending"
+		}
+		info += fmt.Sprintf(", sorted by %s %s", t.grid.columns[t.grid.sortColumn].title, direction)
+	}
+	t.header.SetText(fmt.Sprintf(" %s  %s", tview.Escape(t.array.Path()), tview.Escape(info)))
+}
+
+// InputHandler implements tview.Primitive
+func (t *TableView) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return t.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+		t.grid.InputHandler()(event, setFocus)
+	})
+}
+
+// Focus implements tview.Primitive
+func (t *TableView) Focus(delegate func(p tview.Primitive)) {
+	t.grid.Focus(delegate)
+}
+
+// Blur implements tview.Primitive
+func (t *TableView) Blur() {
+	t.grid.Blur()
+}
+
+// GetFocusable implements tview.Primitive
+func (t *TableView) GetFocusable() tview.Focusable {
+	return t.grid.GetFocusable()
+}
+
+// tableGrid draws the cells with a fixed header row and index column, the other columns scroll
+// horizontally to keep the current cell in view.
+type tableGrid struct {
+	*tview.Box
+
+	monochrome bool
+	array      nodes.Node
+	columns    []tableColumn
+	rows       []nodes.Node
+	indexes    map[nodes.Node]int
+
+	currentRow    int
+	currentColumn int
+	rowOffset     int
+	columnOffset  int
+
+	sortColumn     int
+	sortDescending bool
+
+	changedFn  func()
+	selectedFn func(node nodes.Node)
+}
+
+func newTableGrid(monochrome bool, selectedFn func(node nodes.Node)) *tableGrid {
+	return &tableGrid{
+		Box:        tview.NewBox(),
+		monochrome: monochrome,
+		sortColumn: -1,
+		selectedFn: selectedFn,
+	}
+}
+
+func (g *tableGrid) open(array nodes.Node) {
+	g.array = array
+	g.rows = append([]nodes.Node{}, array.Children()...)
+	g.indexes = make(map[nodes.Node]int, len(g.rows))
+	for idx, element := range g.rows {
+		g.indexes[element] = idx
+	}
+	g.columns = nil
+	for _, tc := range nodes.TableColumns(array) {
+		column := tableColumn{
+			TableColumn: tc,
+			title:       tc.Key,
+		}
+		switch {
+		case tc.IsValue:
+			column.title = "(value)"
+		case len(tc.Key) == 0:
+			column.title = `""`
+		}
+		column.width = tview.StringWidth(column.title) + 2
+		for _, element := range g.rows {
+			if cell := nodes.TableCell(element, tc); cell != nil {
+				if width := tview.StringWidth(nodes.CellText(cell)); width > column.width {
+					column.width = width
+				}
+			}
+		}
+		if column.width > tableMaxColumnWidth {
+			column.width = tableMaxColumnWidth
+		}
+		g.columns = append(g.columns, column)
+	}
+
+	g.currentRow = 0
+	g.currentColumn = 0
+	g.rowOffset = 0
+	g.columnOffset = 0
+	g.sortColumn = -1
+	g.sortDescending = false
+}
+
+func (g *tableGrid) selectElement(element nodes.Node) {
+	for idx, row := range g.rows {
+		if row == element {
+			g.currentRow = idx
+			return
+		}
+	}
+}
+
+func (g *tableGrid) hiddenCount() int {
+	count := 0
+	for _, column := range g.columns {
+		if column.hidden {
+			count++
+		}
+	}
+	return count
+}
+
+// currentNode is the node of the current cell, or the element if it doesn't have the column.
+func (g *tableGrid) currentNode() nodes.Node {
+	if len(g.rows) == 0 {
+		return nil
+	}
+	element := g.rows[g.currentRow]
+	if len(g.columns) == 0 || g.columns[g.currentColumn].hidden {
+		return element
+	}
+	if cell := nodes.TableCell(element, g.columns[g.currentColumn].TableColumn); cell != nil {
+		return cell
+	}
+	return element
+}
+
+// sortBy cycles the column through ascending, descending and the document order.
+func (g *tableGrid) sortBy(column int) {
+	current := g.rows[g.currentRow]
+
+	switch {
+	case g.sortColumn != column:
+		g.sortColumn = column
+		g.sortDescending = false
+	case g.sortDescending == false:
+		g.sortDescending = true
+	default:
+		g.sortColumn = -1
+	}
+
+	if g.sortColumn < 0 {
+		g.rows = append(g.rows[:0], g.array.Children()...)
+	} else {
+		column := g.columns[g.sortColumn].TableColumn
+		sort.SliceStable(g.rows, func(i, j int) bool {
+			a := nodes.TableCell(g.rows[i], column)
+			b := nodes.TableCell(g.rows[j], column)
+			// Missing values stay last in both directions
+			if g.sortDescending && a != nil && b != nil {
+				return nodes.CompareValues(b, a) < 0
+			}
+			return nodes.CompareValues(a, b) < 0
+		})
+	}
+	g.selectElement(current)
+}
+
+// moveColumn moves the current column to the next visible column in the direction.
+func (g *tableGrid) moveColumn(direction int) {
+	for idx := g.currentColumn + direction; idx >= 0 && idx < len(g.columns); idx += direction {
+		if g.columns[idx].hidden == false {
+			g.currentColumn = idx
+			return
+		}
+	}
+}
+
+func (g *tableGrid) hideCurrentColumn() {
+	if len(g.columns)-g.hiddenCount() < 2 {
+		return
+	}
+	g.columns[g.currentColumn].hidden = true
+	previous := g.currentColumn
+	g.moveColumn(1)
+	if g.currentColumn == previous {
+		g.moveColumn(-1)
+	}
+}
+
+func (g *tableGrid) showAllColumns() {
+	for idx := range g.columns {
+		g.columns[idx].hidden = false
+	}
+}
+
+// Draw implements tview.Primitive
+func (g *tableGrid) Draw(screen tcell.Screen) {
+	g.Box.Draw(screen)
+
+	x, y, width, height := g.GetInnerRect()
+	if len(g.rows) == 0 || len(g.columns) == 0 || height < 2 {
+		tview.Print(screen, " No elements with values", x, y, width, tview.AlignLeft, tview.Styles.SecondaryTextColor)
+		return
+	}
+
+	// Keep the current cell in view
+	visibleRows := height - 1
+	if g.currentRow < g.rowOffset {
+		g.rowOffset = g.currentRow
+	} else if g.currentRow >= g.rowOffset+visibleRows {
+		g.rowOffset = g.currentRow + 1 - visibleRows
+	}
+	if g.currentColumn < g.columnOffset {
+		g.columnOffset = g.currentColumn
+	}
+	for g.columnOffset < g.currentColumn && g.columnsWidth(g.columnOffset, g.currentColumn) > width-tableIndexWidth {
+		g.columnOffset++
+	}
+
+	textColor := tview.Styles.PrimaryTextColor
+	headerColor := tview.Styles.SecondaryTextColor
+	missingColor := tview.Styles.TertiaryTextColor
+
+	tview.Print(screen, "#", x, y, tableIndexWidth-1, tview.AlignRight, headerColor)
+	for rowIdx := g.rowOffset; rowIdx < len(g.rows) && rowIdx-g.rowOffset < visibleRows; rowIdx++ {
+		index := strconv.Itoa(g.indexes[g.rows[rowIdx]])
+		tview.Print(screen, index, x, y+1+rowIdx-g.rowOffset, tableIndexWidth-1, tview.AlignRight, headerColor)
+	}
+
+	columnX := x + tableIndexWidth
+	for colIdx := g.columnOffset; colIdx < len(g.columns) && columnX < x+width; colIdx++ {
+		column := g.columns[colIdx]
+		if column.hidden {
+			continue
+		}
+		columnWidth := column.width
+		if columnX+columnWidth > x+width {
+			columnWidth = x + width - columnX
+		}
+
+		title := column.title
+		if colIdx == g.sortColumn {
+			if g.sortDescending {
+				title += " ▼"
+			} else {
+				title += " ▲"
+			}
+		}
+		tview.Print(screen, tview.Escape(title), columnX, y, columnWidth, tview.AlignLeft, headerColor)
+
+		for rowIdx := g.rowOffset; rowIdx < len(g.rows) && rowIdx-g.rowOffset < visibleRows; rowIdx++ {
+			rowY := y + 1 + rowIdx - g.rowOffset
+			cell := nodes.TableCell(g.rows[rowIdx], column.TableColumn)
+			if cell == nil {
+				tview.Print(screen, "-", columnX, rowY, columnWidth, tview.AlignLeft, missingColor)
+			} else {
+				tview.Print(screen, tview.Escape(nodes.CellText(cell)), columnX, rowY, columnWidth, tview.AlignLeft, g.cellColor(cell, textColor))
+			}
+
+			if rowIdx == g.currentRow && colIdx == g.currentColumn {
+				g.highlight(screen, columnX, rowY, columnWidth)
+			}
+		}
+		columnX += column.width + 1
+	}
+}
+
+// columnsWidth is the width of the visible columns from..to, including both.
+func (g *tableGrid) columnsWidth(from int, to int) int {
+	width := 0
+	for idx := from; idx <= to; idx++ {
+		if g.columns[idx].hidden == false {
+			width += g.columns[idx].width + 1
+		}
+	}
+	return width
+}
+
+func (g *tableGrid) cellColor(cell nodes.Node, textColor tcell.Color) tcell.Color {
+	if g.monochrome {
+		return textColor
+	}
+	if nodes.IsContainer(cell) {
+		return tview.Styles.TertiaryTextColor
+	}
+	return textColor
+}
+
+func (g *tableGrid) highlight(screen tcell.Screen, x int, y int, width int) {
+	for bx := 0; bx < width; bx++ {
+		m, c, style, _ := screen.GetContent(x+bx, y)
+		style = style.Background(tview.Styles.PrimaryTextColor).Foreground(tview.Styles.PrimitiveBackgroundColor)
+		screen.SetContent(x+bx, y, m, c, style)
+	}
+}
+
+// InputHandler implements tview.Primitive
+func (g *tableGrid) InputHandler() func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+	return g.WrapInputHandler(func(event *tcell.EventKey, setFocus func(p tview.Primitive)) {
+		if len(g.rows) == 0 || len(g.columns) == 0 {
+			return
+		}
+
+		_, _, _, height := g.GetInnerRect()
+		page := height - 1
+		if page < 1 {
+			page = 1
+		}
+
+		row := g.currentRow
+		switch event.Key() {
+		case tcell.KeyUp:
+			row--
+		case tcell.KeyDown:
+			row++
+		case tcell.KeyLeft:
+			g.moveColumn(-1)
+		case tcell.KeyRight:
+			g.moveColumn(1)
+		case tcell.KeyHome:
+			row = 0
+		case tcell.KeyEnd:
+			row = len(g.rows) - 1
+		case tcell.KeyPgUp:
+			row -= page
+		case tcell.KeyPgDn:
+			row += page
+
+		case tcell.KeyEnter:
+			if g.selectedFn != nil {
+				g.selectedFn(g.currentNode())
+			}
+			return
+
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'k':
+				row--
+			case 'j':
+				row++
+			case 'h':
+				g.moveColumn(-1)
+			case 'l':
+				g.moveColumn(1)
+			case 's':
+				g.sortBy(g.currentColumn)
+				row = g.currentRow
+			case 'x':
+				g.hideCurrentColumn()
+			case 'a':
+				g.showAllColumns()
+			}
+		}
+
+		if row < 0 {
+			row = 0
+		} else if row >= len(g.rows) {
+			row = len(g.rows) - 1
+		}
+		g.currentRow = row
+
+		if g.changedFn != nil {
+			g.changedFn()
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package widgets
+
+import (
+	"sort"
+
+	"github.com/benweidig/trex/nodes"
+	"github.com/benweidig/trex/schema"
+	"github.com/benweidig/trex/ui"
+	"github.com/rivo/tview"
+)
+
+type violationItem struct {
+	node  nodes.Node
+	label string
+}
+
+// Label implements interface ui.ListItem
+func (i *violationItem) Label() string {
+	return i.label
+}
+
+// ViolationList shows all schema violations of a document, selecting one jumps to its node.
+type ViolationList struct {
+	*ui.List
+}
+
+// NewViolationList builds a new ViolationList, selectedFn is called with the node of the chosen violation.
+func NewViolationList(selectedFn func(node nodes.Node)) *ViolationList {
+	l := &ViolationList{
+		List: ui.NewList(),
+	}
+	l.SetBorder(true)
+	l.SetTitle(" Schema violations ")
+	l.SetRect(0, 0, 80, 20)
+
+	l.SetSelectedFn(func(idx int, item ui.ListItem) {
+		if violation, ok := item.(*violationItem); ok && violation.node != nil {
+			selectedFn(violation.node)
+		}
+	})
+	return l
+}
+
+// Open lists the violations sorted by their pointers, the nodes are looked up in the root.
+func (l *ViolationList) Open(root nodes.Node, violations []schema.Violation) *ViolationList {
+	if len(violations) == 0 {
+		l.SetItems([]ui.ListItem{ui.NewSimpleListItem("  No violations")}, false)
+		return l
+	}
+
+	sorted := make([]schema.Violation, len(violations))
+	copy(sorted, violations)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Pointer < sorted[j].Pointer
+	})
+
+	items := make([]ui.ListItem, len(sorted))
+	for idx, violation := range sorted {
+		items[idx] = &violationItem{
+			node:  nodes.FindByPointer(root, violation.Pointer),
+			label: " " + tview.Escape(violation.String()),
+		}
+	}
+	l.SetItems(items, false)
+	return l
+}